@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	mygrpc "url-shortener/internal/grpc"
+	"url-shortener/internal/tlsconfig"
+)
+
+const (
+	formatCSV   = "csv"
+	formatJSONL = "jsonl"
+)
+
+// record is a single exported mapping. The layout is accepted by url-shortener-import.
+type record struct {
+	ShortURL    string     `json:"short_url"`
+	OriginalURL string     `json:"original_url"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type writer interface {
+	Write(record) error
+	Flush() error
+}
+
+func main() {
+	addr := flag.String("addr", "localhost:8082", "address of the url-shortener gRPC server")
+	file := flag.String("out", "-", "file to write, - for stdout")
+	format := flag.String("format", "", "output format: csv or jsonl (detected from the file extension, jsonl for stdout)")
+	useTLS := flag.Bool("tls", false, "connect over TLS")
+	tlsCA := flag.String("tls-ca", "", "PEM file with the CAs that sign the server certificate, the system roots by default (implies -tls)")
+	tlsCert := flag.String("tls-cert", "", "client certificate for mutual TLS (implies -tls)")
+	tlsKey := flag.String("tls-key", "", "key of the client certificate")
+	flag.Parse()
+
+	if *format == "" {
+		*format = detectFormat(*file)
+	}
+
+	out := os.Stdout
+	if *file != "-" {
+		f, err := os.Create(*file)
+		if err != nil {
+			log.Fatalf("failed to create %s: %v", *file, err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	buf := bufio.NewWriter(out)
+	var w writer
+	switch *format {
+	case formatCSV:
+		w = newCSVWriter(buf)
+	case formatJSONL:
+		w = &jsonlWriter{enc: json.NewEncoder(buf), buf: buf}
+	default:
+		log.Fatalf("unknown format %q, expected %s or %s", *format, formatCSV, formatJSONL)
+	}
+
+	creds := insecure.NewCredentials()
+	if *useTLS || *tlsCA != "" || *tlsCert != "" {
+		tlsConfig, err := tlsconfig.ClientConfig(*tlsCA, *tlsCert, *tlsKey)
+		if err != nil {
+			log.Fatalf("invalid tls flags: %v", err)
+		}
+		creds = credentials.NewTLS(tlsConfig)
+	}
+
+	conn, err := grpc.NewClient(*addr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		log.Fatalf("failed to connect to %s: %v", *addr, err)
+	}
+	defer conn.Close()
+
+	stream, err := mygrpc.NewURLShortenerClient(conn).ExportURLs(context.Background(), &mygrpc.ExportURLsRequest{})
+	if err != nil {
+		log.Fatalf("failed to start export: %v", err)
+	}
+
+	var exported int
+	for {
+		url, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			log.Fatalf("export failed: %v", err)
+		}
+
+		rec := record{
+			ShortURL:    url.ShortUrl,
+			OriginalURL: url.OriginalUrl,
+			CreatedAt:   url.CreatedAt.AsTime(),
+		}
+		if url.ExpiresAt != nil {
+			expiresAt := url.ExpiresAt.AsTime()
+			rec.ExpiresAt = &expiresAt
+		}
+		if err := w.Write(rec); err != nil {
+			log.Fatalf("failed to write record: %v", err)
+		}
+		exported++
+	}
+
+	if err := w.Flush(); err != nil {
+		log.Fatalf("failed to write output: %v", err)
+	}
+	fmt.Fprintf(os.Stderr, "exported: %d\n", exported)
+}
+
+func detectFormat(file string) string {
+	if strings.EqualFold(filepath.Ext(file), ".csv") {
+		return formatCSV
+	}
+	return formatJSONL
+}
+
+type csvWriter struct {
+	w      *csv.Writer
+	buf    *bufio.Writer
+	header bool
+}
+
+func newCSVWriter(buf *bufio.Writer) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(buf), buf: buf}
+}
+
+func (c *csvWriter) writeHeader() error {
+	if c.header {
+		return nil
+	}
+	c.header = true
+	return c.w.Write([]string{"short_url", "original_url", "expires_at", "created_at"})
+}
+
+func (c *csvWriter) Write(r record) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+
+	var expiresAt string
+	if r.ExpiresAt != nil {
+		expiresAt = r.ExpiresAt.Format(time.RFC3339)
+	}
+	return c.w.Write([]string{r.ShortURL, r.OriginalURL, expiresAt, r.CreatedAt.Format(time.RFC3339)})
+}
+
+func (c *csvWriter) Flush() error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	c.w.Flush()
+	if err := c.w.Error(); err != nil {
+		return err
+	}
+	return c.buf.Flush()
+}
+
+type jsonlWriter struct {
+	enc *json.Encoder
+	buf *bufio.Writer
+}
+
+func (j *jsonlWriter) Write(r record) error {
+	return j.enc.Encode(r)
+}
+
+func (j *jsonlWriter) Flush() error {
+	return j.buf.Flush()
+}
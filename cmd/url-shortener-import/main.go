@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	mygrpc "url-shortener/internal/grpc"
+	"url-shortener/internal/tlsconfig"
+)
+
+const (
+	formatCSV   = "csv"
+	formatJSONL = "jsonl"
+)
+
+// record is a single alias→URL pair read from the input file.
+type record struct {
+	ShortURL    string     `json:"short_url"`
+	OriginalURL string     `json:"original_url"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+	line        int        // in the input file, starting at 1
+}
+
+// reader calls fn for every record of the input and skip for every malformed
+// row, which does not stop reading. Only an input error or an error of fn
+// stops it.
+type reader func(r io.Reader, fn func(record) error, skip func(line int, err error)) error
+
+func main() {
+	addr := flag.String("addr", "localhost:8082", "address of the url-shortener gRPC server")
+	file := flag.String("file", "-", "file to import, - for stdin")
+	format := flag.String("format", "", "input format: csv or jsonl (detected from the file extension, jsonl for stdin)")
+	useTLS := flag.Bool("tls", false, "connect over TLS")
+	tlsCA := flag.String("tls-ca", "", "PEM file with the CAs that sign the server certificate, the system roots by default (implies -tls)")
+	tlsCert := flag.String("tls-cert", "", "client certificate for mutual TLS (implies -tls)")
+	tlsKey := flag.String("tls-key", "", "key of the client certificate")
+	flag.Parse()
+
+	if *format == "" {
+		*format = detectFormat(*file)
+	}
+
+	in := os.Stdin
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			log.Fatalf("failed to open %s: %v", *file, err)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	var read reader
+	switch *format {
+	case formatCSV:
+		read = readCSV
+	case formatJSONL:
+		read = readJSONL
+	default:
+		log.Fatalf("unknown format %q, expected %s or %s", *format, formatCSV, formatJSONL)
+	}
+
+	creds := insecure.NewCredentials()
+	if *useTLS || *tlsCA != "" || *tlsCert != "" {
+		tlsConfig, err := tlsconfig.ClientConfig(*tlsCA, *tlsCert, *tlsKey)
+		if err != nil {
+			log.Fatalf("invalid tls flags: %v", err)
+		}
+		creds = credentials.NewTLS(tlsConfig)
+	}
+
+	conn, err := grpc.NewClient(*addr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		log.Fatalf("failed to connect to %s: %v", *addr, err)
+	}
+	defer conn.Close()
+
+	stream, err := mygrpc.NewURLShortenerClient(conn).ImportURLs(context.Background())
+	if err != nil {
+		log.Fatalf("failed to start import: %v", err)
+	}
+
+	// lines maps the index of a sent record to its line in the input.
+	var lines []int
+	var invalid int64
+	err = read(bufio.NewReader(in), func(r record) error {
+		req := &mygrpc.ImportURLsRequest{ShortUrl: r.ShortURL, OriginalUrl: r.OriginalURL}
+		if r.ExpiresAt != nil {
+			req.ExpiresAt = timestamppb.New(*r.ExpiresAt)
+		}
+		if r.CreatedAt != nil {
+			req.CreatedAt = timestamppb.New(*r.CreatedAt)
+		}
+		lines = append(lines, r.line)
+		return stream.Send(req)
+	}, func(line int, err error) {
+		invalid++
+		fmt.Fprintf(os.Stderr, "line %d: skipped: %v\n", line, err)
+	})
+	if err != nil && !errors.Is(err, io.EOF) {
+		// Rows sent so far are still committed and reported below.
+		log.Printf("failed to read input, stopping the import: %v", err)
+	}
+
+	// A failed Send reports io.EOF; the actual error comes from CloseAndRecv.
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		log.Fatalf("import failed: %v", err)
+	}
+
+	for _, c := range resp.Conflicts {
+		line := c.Index + 1
+		if c.Index >= 0 && c.Index < int64(len(lines)) {
+			line = int64(lines[c.Index])
+		}
+		fmt.Fprintf(os.Stderr, "line %d: %s -> %s: %s: %s\n",
+			line, c.ShortUrl, c.OriginalUrl, codes.Code(c.Code), c.Message)
+	}
+	if omitted := resp.ConflictCount - int64(len(resp.Conflicts)); omitted > 0 {
+		fmt.Fprintf(os.Stderr, "%d more conflicts not shown\n", omitted)
+	}
+	fmt.Printf("imported: %d, conflicts: %d, invalid: %d\n", resp.Imported, resp.ConflictCount, invalid)
+}
+
+// detectFormat matches url-shortener-export, so that its output can be piped
+// into the import as is.
+func detectFormat(file string) string {
+	if strings.EqualFold(filepath.Ext(file), ".csv") {
+		return formatCSV
+	}
+	return formatJSONL
+}
+
+// readCSV reads short_url,original_url[,expires_at[,created_at]] rows as written
+// by url-shortener-export. A header row is skipped.
+func readCSV(r io.Reader, fn func(record) error, skip func(line int, err error)) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	for first := true; ; first = false {
+		row, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			skip(parseErr.StartLine, parseErr.Err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		line, _ := cr.FieldPos(0)
+		if first && len(row) > 0 && row[0] == "short_url" {
+			continue
+		}
+		if len(row) < 2 || len(row) > 4 {
+			skip(line, fmt.Errorf("expected 2 to 4 columns, got %d", len(row)))
+			continue
+		}
+
+		rec := record{ShortURL: row[0], OriginalURL: row[1], line: line}
+		if len(row) >= 3 && row[2] != "" {
+			expiresAt, err := time.Parse(time.RFC3339, row[2])
+			if err != nil {
+				skip(line, fmt.Errorf("invalid expires_at: %w", err))
+				continue
+			}
+			rec.ExpiresAt = &expiresAt
+		}
+		if len(row) == 4 && row[3] != "" {
+			createdAt, err := time.Parse(time.RFC3339, row[3])
+			if err != nil {
+				skip(line, fmt.Errorf("invalid created_at: %w", err))
+				continue
+			}
+			rec.CreatedAt = &createdAt
+		}
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+}
+
+func readJSONL(r io.Reader, fn func(record) error, skip func(line int, err error)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var rec record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			skip(line, err)
+			continue
+		}
+		rec.line = line
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
@@ -1,25 +1,38 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"golang.org/x/exp/slog"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/reflection"
 
+	"url-shortener/internal/alias"
+	"url-shortener/internal/analytics"
+	"url-shortener/internal/auth"
 	"url-shortener/internal/config"
 	mygrpc "url-shortener/internal/grpc"
+	"url-shortener/internal/health"
+	"url-shortener/internal/httpserver"
 	"url-shortener/internal/lib/logger/handlers/slogpretty"
 	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/metrics"
 	"url-shortener/internal/service"
 	"url-shortener/internal/storage"
 	"url-shortener/internal/storage/memory"
 	"url-shortener/internal/storage/postgres"
+	"url-shortener/internal/tlsconfig"
+	"url-shortener/internal/tracing"
 )
 
 const (
@@ -37,31 +50,156 @@ func main() {
 	)
 	slogLogger.Debug("debug messages are enabled")
 
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		slogLogger.Error("failed to set up tracing", sl.Err(err))
+		os.Exit(1)
+	}
+
 	var urlStorage storage.URLSaverURLGetter
+	var clickStorage storage.ClickStorage
+	var aliasSequence alias.Sequence
+	var aliasPool storage.AliasPool
 	switch cfg.StorageType {
 	case "memory":
 		slogLogger.Info("using in-memory storage")
-		urlStorage = memory.New()
+		memoryStorage := memory.New()
+		urlStorage = memoryStorage
+		clickStorage = memoryStorage
+		aliasSequence = memoryStorage
+		aliasPool = memoryStorage
 	case "postgres":
 		slogLogger.Info("using postgres storage")
 		dataSourceName := cfg.PostgresURL
 
-		postgresStorage, err := postgres.New(dataSourceName)
+		postgresStorage, err := postgres.New(dataSourceName, postgres.WithTimeouts(postgres.Timeouts{
+			Read:  cfg.StorageTimeouts.Read,
+			Write: cfg.StorageTimeouts.Write,
+			Batch: cfg.StorageTimeouts.Batch,
+		}))
 		if err != nil {
 			slogLogger.Error("failed to init postgres storage", sl.Err(err))
 			os.Exit(1)
 		}
 		urlStorage = postgresStorage
+		clickStorage = postgresStorage
+		aliasSequence = postgresStorage
+		aliasPool = postgresStorage
 	default:
 		slogLogger.Error("invalid storage type", slog.String("storage_type", cfg.StorageType))
 		os.Exit(1)
 	}
 
-	urlShortenerService := service.NewURLShortenerService(urlStorage, cfg.ShortURLLength)
+	aliasAlphabet, err := alias.ParseAlphabet(cfg.AliasGeneration.Alphabet)
+	if err != nil {
+		slogLogger.Error("invalid alias alphabet", sl.Err(err))
+		os.Exit(1)
+	}
+
+	var aliasGenerator service.AliasGenerator
+	switch cfg.AliasGeneration.Strategy {
+	case "random":
+		aliasGenerator = alias.NewRandom(aliasAlphabet)
+	case "counter":
+		aliasGenerator = alias.NewCounter(aliasAlphabet, aliasSequence)
+	case "hash":
+		aliasGenerator = alias.NewHash(aliasAlphabet)
+	case "time":
+		aliasGenerator = alias.NewTimeSortable(aliasAlphabet)
+	default:
+		slogLogger.Error("invalid alias generation strategy", slog.String("strategy", cfg.AliasGeneration.Strategy))
+		os.Exit(1)
+	}
+	slogLogger.Info("using alias generation strategy", slog.String("strategy", cfg.AliasGeneration.Strategy))
+
+	aliasPolicy := service.AliasPolicy{
+		MinLength: cfg.CustomAlias.MinLength,
+		MaxLength: cfg.CustomAlias.MaxLength,
+		Reserved:  cfg.CustomAlias.ReservedWords,
+	}
+	if cfg.CustomAlias.AllowedCharacters != "" {
+		aliasPolicy.Characters, err = alias.ParseAlphabet(cfg.CustomAlias.AllowedCharacters)
+		if err != nil {
+			slogLogger.Error("invalid custom alias characters", sl.Err(err))
+			os.Exit(1)
+		}
+	}
+	switch cfg.CustomAlias.Case {
+	case "preserve":
+		aliasPolicy.Case = service.AliasCasePreserve
+	case "lower":
+		aliasPolicy.Case = service.AliasCaseLower
+	case "reject_upper":
+		aliasPolicy.Case = service.AliasCaseRejectUpper
+	default:
+		slogLogger.Error("invalid custom alias case policy", slog.String("case", cfg.CustomAlias.Case))
+		os.Exit(1)
+	}
+
+	clickRecorder := analytics.NewRecorder(clickStorage,
+		cfg.Analytics.BufferSize, cfg.Analytics.BatchSize, cfg.Analytics.FlushInterval)
+
+	serviceOpts := []service.Option{
+		service.WithAliasGenerator(aliasGenerator),
+		service.WithAliasReuse(cfg.AllowAliasReuse),
+		service.WithClickRecorder(clickRecorder),
+		service.WithMaxBatchSize(cfg.MaxBatchSize),
+		service.WithMaxAttempts(cfg.AliasGeneration.MaxAttempts),
+		service.WithURLPolicy(service.URLPolicy{
+			AllowedSchemes:     cfg.URLPolicy.AllowedSchemes,
+			StripTrailingSlash: cfg.URLPolicy.StripTrailingSlash,
+			SortQuery:          cfg.URLPolicy.SortQuery,
+		}),
+		service.WithAliasPolicy(aliasPolicy),
+	}
+	if cfg.AliasGeneration.CheckCharacter {
+		serviceOpts = append(serviceOpts, service.WithCheckCharacter(aliasAlphabet))
+	}
+	if cfg.AliasPool.Enabled {
+		if cfg.AliasGeneration.Strategy == "hash" {
+			slogLogger.Error("alias pool cannot be used with hash aliases")
+			os.Exit(1)
+		}
+		serviceOpts = append(serviceOpts, service.WithAliasPool(aliasPool, cfg.AliasPool.Size, cfg.AliasPool.Watermark))
+	}
+	if cfg.AliasGeneration.AutoGrow {
+		serviceOpts = append(serviceOpts,
+			service.WithAutoGrow(cfg.AliasGeneration.GrowThreshold, cfg.AliasGeneration.MaxLength))
+	}
+	urlShortenerService := service.NewURLShortenerService(
+		metrics.NewStorage(cfg.StorageType, urlStorage), cfg.ShortURLLength, serviceOpts...)
+
+	if _, err := urlShortenerService.BackfillCanonicalURLs(context.Background()); err != nil {
+		slogLogger.Error("failed to backfill canonical urls", sl.Err(err))
+		os.Exit(1)
+	}
+
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	go urlShortenerService.RunExpirationSweeper(backgroundCtx, cfg.SweepInterval)
+	go urlShortenerService.RunAliasPool(backgroundCtx, cfg.AliasPool.RefillInterval)
 
-	grpcServer := grpc.NewServer()
+	grpcOpts := []grpc.ServerOption{
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(metrics.UnaryServerInterceptor(), auth.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(metrics.StreamServerInterceptor(), auth.StreamServerInterceptor()),
+	}
+	if tlsCfg := cfg.HTTPServer.TLS; tlsCfg.CertFile != "" || tlsCfg.KeyFile != "" || tlsCfg.ClientCAFile != "" {
+		certReloader, err := tlsconfig.NewReloader(tlsCfg)
+		if err != nil {
+			slogLogger.Error("invalid tls configuration", sl.Err(err))
+			os.Exit(1)
+		}
+		go certReloader.Run(backgroundCtx, tlsCfg.ReloadInterval)
+		grpcOpts = append(grpcOpts, grpc.Creds(credentials.NewTLS(certReloader.Config())))
+		slogLogger.Info("gRPC TLS enabled", slog.Bool("mutual", tlsCfg.ClientCAFile != ""))
+	}
+	grpcServer := grpc.NewServer(grpcOpts...)
 	urlShortenerServer := mygrpc.NewURLShortenerServer(urlShortenerService)
 	mygrpc.RegisterURLShortenerServer(grpcServer, urlShortenerServer)
+	healthChecker := health.NewChecker(urlStorage, cfg.HealthCheck.Timeout)
+	healthChecker.Register(grpcServer)
+	go healthChecker.Run(backgroundCtx, cfg.HealthCheck.Interval)
 	reflection.Register(grpcServer)
 
 	lis, err := net.Listen("tcp", cfg.HTTPServer.Address)
@@ -76,13 +214,61 @@ func main() {
 		}
 	}()
 
+	httpServer := httpserver.NewServer(cfg.HTTPServer, urlShortenerService)
+	slogLogger.Info("HTTP server listening", slog.String("address", cfg.HTTPServer.HTTPAddress))
+
+	go func() {
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("failed to serve http: %v", err)
+		}
+	}()
+
+	adminMux := http.NewServeMux()
+	adminMux.Handle("GET /metrics", metrics.Handler())
+	adminMux.Handle("GET /healthz", healthChecker.LivenessHandler())
+	adminMux.Handle("GET /readyz", healthChecker.ReadinessHandler())
+	adminServer := &http.Server{
+		Addr:              cfg.HTTPServer.AdminAddress,
+		Handler:           adminMux,
+		ReadHeaderTimeout: cfg.HTTPServer.Timeout,
+	}
+	slogLogger.Info("admin server listening", slog.String("address", cfg.HTTPServer.AdminAddress))
+
+	go func() {
+		if err := adminServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("failed to serve admin http: %v", err)
+		}
+	}()
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
+	healthChecker.Shutdown()
+	stopBackground()
+
+	slogLogger.Info("Gracefully shutting down HTTP server...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.HTTPServer.Timeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		slogLogger.Error("failed to shut down HTTP server", sl.Err(err))
+	}
+
 	slogLogger.Info("Gracefully shutting down gRPC server...")
 	grpcServer.GracefulStop()
 	slogLogger.Info("gRPC server stopped")
 
+	// The admin server goes last, so that the readiness probe reports the
+	// shutdown while the other servers drain.
+	if err := adminServer.Shutdown(shutdownCtx); err != nil {
+		slogLogger.Error("failed to shut down admin server", sl.Err(err))
+	}
+
+	clickRecorder.Close()
+
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slogLogger.Error("failed to shut down tracing", sl.Err(err))
+	}
+
 	fmt.Println("gRPC  server is closing")
 
 }
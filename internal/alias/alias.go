@@ -0,0 +1,99 @@
+// Package alias implements the strategies for generating aliases of new short
+// URLs.
+package alias
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+
+	"url-shortener/internal/lib/random"
+)
+
+// minRandomSuffix is the least number of random characters following the
+// timestamp of a time-sortable alias.
+const minRandomSuffix = 3
+
+// maxTimestamp is the first millisecond that time-sortable aliases need not
+// represent.
+var maxTimestamp = big.NewInt(time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli())
+
+// Random generates unguessable aliases from a cryptographically secure source.
+type Random struct {
+	alphabet *Alphabet
+}
+
+func NewRandom(alphabet *Alphabet) *Random {
+	return &Random{alphabet: alphabet}
+}
+
+func (g *Random) Generate(ctx context.Context, originalURL string, length int, attempt int) (string, error) {
+	return random.NewString(g.alphabet.chars, length), nil
+}
+
+// Sequence hands out increasing numbers.
+type Sequence interface {
+	NextAliasID(ctx context.Context) (int64, error)
+}
+
+// Counter generates the shortest possible aliases by encoding the next value of
+// a sequence. The requested length is ignored.
+type Counter struct {
+	alphabet *Alphabet
+	seq      Sequence
+}
+
+func NewCounter(alphabet *Alphabet, seq Sequence) *Counter {
+	return &Counter{alphabet: alphabet, seq: seq}
+}
+
+func (g *Counter) Generate(ctx context.Context, originalURL string, length int, attempt int) (string, error) {
+	id, err := g.seq.NextAliasID(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get next alias id: %w", err)
+	}
+	return g.alphabet.encode(big.NewInt(id), 1), nil
+}
+
+// Hash generates reproducible aliases from the SHA-256 of the original URL, so
+// the same URL always maps to the same alias. A collision with another URL is
+// resolved by hashing the attempt number along with the URL.
+type Hash struct {
+	alphabet *Alphabet
+}
+
+func NewHash(alphabet *Alphabet) *Hash {
+	return &Hash{alphabet: alphabet}
+}
+
+func (g *Hash) Generate(ctx context.Context, originalURL string, length int, attempt int) (string, error) {
+	input := originalURL
+	if attempt > 0 {
+		input += "#" + strconv.Itoa(attempt)
+	}
+	sum := sha256.Sum256([]byte(input))
+	s := g.alphabet.encode(new(big.Int).SetBytes(sum[:]), length)
+	return s[:min(length, len(s))], nil
+}
+
+// TimeSortable generates aliases that sort by creation time: a fixed width
+// millisecond timestamp followed by random characters that fill up the
+// requested length, but at least minRandomSuffix of them.
+type TimeSortable struct {
+	alphabet *Alphabet
+	width    int
+	now      func() time.Time
+}
+
+func NewTimeSortable(alphabet *Alphabet) *TimeSortable {
+	return &TimeSortable{alphabet: alphabet, width: alphabet.width(maxTimestamp), now: time.Now}
+}
+
+func (g *TimeSortable) Generate(ctx context.Context, originalURL string, length int, attempt int) (string, error) {
+	ts := g.alphabet.encode(big.NewInt(g.now().UnixMilli()), g.width)
+	suffix := max(length-g.width, minRandomSuffix)
+	return ts + random.NewString(g.alphabet.chars, suffix), nil
+}
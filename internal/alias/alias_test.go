@@ -0,0 +1,83 @@
+package alias
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sequence struct {
+	last int64
+}
+
+func (s *sequence) NextAliasID(ctx context.Context) (int64, error) {
+	s.last++
+	return s.last, nil
+}
+
+func mustAlphabet(t *testing.T, chars string) *Alphabet {
+	t.Helper()
+	a, err := ParseAlphabet(chars)
+	require.NoError(t, err)
+	return a
+}
+
+func TestCounter(t *testing.T) {
+	g := NewCounter(mustAlphabet(t, "base62"), &sequence{last: 59})
+
+	var got []string
+	for i := 0; i < 3; i++ {
+		s, err := g.Generate(context.Background(), "https://example.com", 10, 0)
+		require.NoError(t, err)
+		got = append(got, s)
+	}
+
+	assert.Equal(t, []string{"y", "z", "10"}, got)
+}
+
+func TestHash(t *testing.T) {
+	ctx := context.Background()
+	g := NewHash(mustAlphabet(t, "base62"))
+
+	s1, err := g.Generate(ctx, "https://example.com", 10, 0)
+	require.NoError(t, err)
+	s2, err := g.Generate(ctx, "https://example.com", 10, 0)
+	require.NoError(t, err)
+	retry, err := g.Generate(ctx, "https://example.com", 10, 1)
+	require.NoError(t, err)
+	other, err := g.Generate(ctx, "https://example.net", 10, 0)
+	require.NoError(t, err)
+
+	assert.Len(t, s1, 10)
+	assert.Equal(t, s1, s2)
+	assert.NotEqual(t, s1, retry)
+	assert.NotEqual(t, s1, other)
+}
+
+func TestTimeSortable(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	g := NewTimeSortable(mustAlphabet(t, "human-safe"))
+	g.now = func() time.Time {
+		now = now.Add(time.Millisecond)
+		return now
+	}
+	require.Equal(t, 8, g.width)
+
+	var got []string
+	for i := 0; i < 100; i++ {
+		s, err := g.Generate(context.Background(), "https://example.com", 12, 0)
+		require.NoError(t, err)
+		require.Len(t, s, 12)
+		require.True(t, g.alphabet.Contains(s), "alias contains invalid characters")
+		got = append(got, s)
+	}
+	assert.True(t, sort.StringsAreSorted(got))
+
+	short, err := g.Generate(context.Background(), "https://example.com", 5, 0)
+	require.NoError(t, err)
+	assert.Len(t, short, g.width+minRandomSuffix)
+}
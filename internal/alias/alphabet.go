@@ -0,0 +1,145 @@
+package alias
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// Alphabet presets accepted by ParseAlphabet.
+const (
+	DefaultAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_"
+	Base62Alphabet  = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	// HumanSafeAlphabet leaves out characters that are easily confused when
+	// read aloud or printed: 0, 1, I, O, l, o and _.
+	HumanSafeAlphabet = "23456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnpqrstuvwxyz"
+)
+
+var presets = map[string]string{
+	"default":    DefaultAlphabet,
+	"base62":     Base62Alphabet,
+	"human-safe": HumanSafeAlphabet,
+}
+
+// Alphabet is the set of characters aliases are made of. Its characters are
+// kept in byte order, so fixed width numbers encoded in it sort like the
+// numbers themselves.
+type Alphabet struct {
+	chars string
+	index [256]int16 // position of a character in chars plus one, zero if absent
+}
+
+// ParseAlphabet returns the alphabet of a preset name ("default", "base62" or
+// "human-safe") or made of the given characters.
+func ParseAlphabet(s string) (*Alphabet, error) {
+	if chars, ok := presets[s]; ok {
+		s = chars
+	}
+	return NewAlphabet(s)
+}
+
+// MustParseAlphabet is like ParseAlphabet but panics on an invalid alphabet.
+func MustParseAlphabet(s string) *Alphabet {
+	a, err := ParseAlphabet(s)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// NewAlphabet returns the alphabet made of chars. The characters must be
+// unreserved in URLs and must not repeat.
+func NewAlphabet(chars string) (*Alphabet, error) {
+	if len(chars) < 2 {
+		return nil, fmt.Errorf("alphabet must have at least 2 characters")
+	}
+
+	b := []byte(chars)
+	sort.Slice(b, func(i, j int) bool { return b[i] < b[j] })
+
+	a := &Alphabet{chars: string(b)}
+	for i, c := range b {
+		if !isUnreserved(c) {
+			return nil, fmt.Errorf("alphabet character %q is not allowed in urls", c)
+		}
+		if a.index[c] != 0 {
+			return nil, fmt.Errorf("alphabet character %q is repeated", c)
+		}
+		a.index[c] = int16(i + 1)
+	}
+	return a, nil
+}
+
+func (a *Alphabet) String() string {
+	return a.chars
+}
+
+// Contains reports whether s is made of the characters of the alphabet only.
+func (a *Alphabet) Contains(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if a.index[s[i]] == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckCharacter returns the Luhn mod N check character of s. It detects every
+// single mistyped character and most swaps of adjacent characters. ok is false
+// if s has characters outside the alphabet.
+func (a *Alphabet) CheckCharacter(s string) (c byte, ok bool) {
+	n := len(a.chars)
+	factor := 2
+	sum := 0
+	for i := len(s) - 1; i >= 0; i-- {
+		pos := int(a.index[s[i]]) - 1
+		if pos < 0 {
+			return 0, false
+		}
+		addend := factor * pos
+		sum += addend/n + addend%n
+		factor = 3 - factor
+	}
+	return a.chars[(n-sum%n)%n], true
+}
+
+// Valid reports whether the last character of s is the check character of the
+// rest.
+func (a *Alphabet) Valid(s string) bool {
+	if len(s) < 2 {
+		return false
+	}
+	c, ok := a.CheckCharacter(s[:len(s)-1])
+	return ok && c == s[len(s)-1]
+}
+
+// encode returns n in the alphabet, left padded to at least width digits.
+func (a *Alphabet) encode(n *big.Int, width int) string {
+	var digits []byte
+	base := big.NewInt(int64(len(a.chars)))
+	mod := new(big.Int)
+	n = new(big.Int).Set(n)
+	for n.Sign() > 0 {
+		n.DivMod(n, base, mod)
+		digits = append(digits, a.chars[mod.Int64()])
+	}
+	for len(digits) < width {
+		digits = append(digits, a.chars[0])
+	}
+	for i, j := 0, len(digits)-1; i < j; i, j = i+1, j-1 {
+		digits[i], digits[j] = digits[j], digits[i]
+	}
+	return string(digits)
+}
+
+// width returns the number of digits needed to encode every number below n.
+func (a *Alphabet) width(n *big.Int) int {
+	return len(a.encode(new(big.Int).Sub(n, big.NewInt(1)), 1))
+}
+
+// isUnreserved reports whether c may appear in a URL path unescaped (RFC 3986).
+func isUnreserved(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+		strings.IndexByte("-._~", c) >= 0
+}
@@ -0,0 +1,77 @@
+package alias
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAlphabet(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{name: "preset", input: "human-safe", expected: HumanSafeAlphabet},
+		{name: "custom characters are sorted", input: "cba-", expected: "-abc"},
+		{name: "too short", input: "a", wantErr: true},
+		{name: "repeated character", input: "abca", wantErr: true},
+		{name: "reserved character", input: "ab/", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := ParseAlphabet(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, a.String())
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	a := mustAlphabet(t, "base62")
+
+	tests := []struct {
+		n        int64
+		width    int
+		expected string
+	}{
+		{n: 0, width: 1, expected: "0"},
+		{n: 61, width: 1, expected: "z"},
+		{n: 62, width: 1, expected: "10"},
+		{n: 62, width: 4, expected: "0010"},
+		{n: 3843, width: 0, expected: "zz"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, a.encode(big.NewInt(tt.n), tt.width))
+	}
+}
+
+func TestCheckCharacter(t *testing.T) {
+	a := mustAlphabet(t, "human-safe")
+
+	c, ok := a.CheckCharacter("Xk7fQ2mn")
+	require.True(t, ok)
+	alias := "Xk7fQ2mn" + string(c)
+	assert.True(t, a.Valid(alias))
+
+	// Every single mistyped character is detected.
+	for i := 0; i < len(alias); i++ {
+		for j := 0; j < len(a.chars); j++ {
+			if a.chars[j] == alias[i] {
+				continue
+			}
+			typo := alias[:i] + string(a.chars[j]) + alias[i+1:]
+			assert.False(t, a.Valid(typo), "typo %q is not detected", typo)
+		}
+	}
+
+	assert.False(t, a.Valid("Xk7fQ2mnl"), "character outside of the alphabet")
+	assert.False(t, a.Valid("X"))
+}
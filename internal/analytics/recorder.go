@@ -0,0 +1,149 @@
+package analytics
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"url-shortener/internal/storage"
+)
+
+type ClientInfo struct {
+	Referrer      string
+	UserAgent     string
+	ClientAddress string
+}
+
+type clientInfoKey struct{}
+
+// WithClientInfo attaches the caller details that end up in click events.
+func WithClientInfo(ctx context.Context, info ClientInfo) context.Context {
+	return context.WithValue(ctx, clientInfoKey{}, info)
+}
+
+func ClientInfoFromContext(ctx context.Context) ClientInfo {
+	info, _ := ctx.Value(clientInfoKey{}).(ClientInfo)
+	return info
+}
+
+// Recorder buffers click events and writes them to storage in batches from a
+// background goroutine, so Record never waits on the database. Events that do
+// not fit into the buffer are dropped.
+type Recorder struct {
+	store         storage.ClickStorage
+	events        chan storage.Click
+	flushes       chan chan struct{}
+	batchSize     int
+	flushInterval time.Duration
+	dropped       atomic.Int64
+	closeOnce     sync.Once
+	mu            sync.RWMutex // guards events against sends after Close
+	closed        bool
+	done          chan struct{}
+}
+
+func NewRecorder(store storage.ClickStorage, bufferSize int, batchSize int, flushInterval time.Duration) *Recorder {
+	r := &Recorder{
+		store:         store,
+		events:        make(chan storage.Click, bufferSize),
+		flushes:       make(chan chan struct{}),
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		done:          make(chan struct{}),
+	}
+	go r.run()
+	return r
+}
+
+// Record queues a click for writing. Clicks recorded after Close are dropped.
+func (r *Recorder) Record(click storage.Click) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		return
+	}
+
+	select {
+	case r.events <- click:
+	default:
+		if r.dropped.Add(1)%1000 == 1 {
+			log.Printf("click buffer is full, %d clicks dropped so far", r.dropped.Load())
+		}
+	}
+}
+
+// Flush blocks until every click recorded so far is written to storage.
+func (r *Recorder) Flush() {
+	ack := make(chan struct{})
+	select {
+	case r.flushes <- ack:
+		<-ack
+	case <-r.done:
+	}
+}
+
+// Close writes the remaining buffered clicks and stops the background goroutine.
+func (r *Recorder) Close() {
+	r.closeOnce.Do(func() {
+		r.mu.Lock()
+		r.closed = true
+		close(r.events)
+		r.mu.Unlock()
+		<-r.done
+	})
+}
+
+func (r *Recorder) Stats(ctx context.Context, shortURL string, since time.Time) (storage.ClickStats, error) {
+	return r.store.GetClickStats(ctx, shortURL, since)
+}
+
+func (r *Recorder) run() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]storage.Click, 0, r.batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := r.store.SaveClicks(context.Background(), batch); err != nil {
+			log.Printf("failed to save %d clicks: %v", len(batch), err)
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case click, ok := <-r.events:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, click)
+			if len(batch) >= r.batchSize {
+				flush()
+			}
+		case ack := <-r.flushes:
+			for drained := false; !drained; {
+				select {
+				case click, ok := <-r.events:
+					if !ok {
+						drained = true
+						break
+					}
+					batch = append(batch, click)
+				default:
+					drained = true
+				}
+			}
+			flush()
+			close(ack)
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// Package auth carries the identity of authenticated callers in the request
+// context, so that handlers can authorize them. Callers are identified by the
+// client certificate verified during the mutual TLS handshake.
+package auth
+
+import (
+	"context"
+	"crypto/x509"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// Identity is the identity of a caller taken from its client certificate.
+type Identity struct {
+	Subject    string // distinguished name
+	CommonName string
+	DNSNames   []string
+	URIs       []string // e.g. SPIFFE IDs
+	Emails     []string
+}
+
+type identityKey struct{}
+
+// WithIdentity attaches the identity of the caller.
+func WithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFromContext returns the identity of the caller. ok is false for
+// callers that did not authenticate with a client certificate.
+func IdentityFromContext(ctx context.Context) (identity Identity, ok bool) {
+	identity, ok = ctx.Value(identityKey{}).(Identity)
+	return identity, ok
+}
+
+// IdentityFromCertificate maps a verified client certificate to an identity.
+func IdentityFromCertificate(cert *x509.Certificate) Identity {
+	identity := Identity{
+		Subject:    cert.Subject.String(),
+		CommonName: cert.Subject.CommonName,
+		DNSNames:   cert.DNSNames,
+		Emails:     cert.EmailAddresses,
+	}
+	for _, uri := range cert.URIs {
+		identity.URIs = append(identity.URIs, uri.String())
+	}
+	return identity
+}
+
+// UnaryServerInterceptor attaches the identity of callers with a verified
+// client certificate to the request context.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(withPeerIdentity(ctx), req)
+	}
+}
+
+// StreamServerInterceptor is UnaryServerInterceptor for streaming RPCs.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := withPeerIdentity(ss.Context())
+		if ctx == ss.Context() {
+			return handler(srv, ss)
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func withPeerIdentity(ctx context.Context) context.Context {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ctx
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return ctx
+	}
+	return WithIdentity(ctx, IdentityFromCertificate(tlsInfo.State.VerifiedChains[0][0]))
+}
+
+// serverStream overrides the context of a stream.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
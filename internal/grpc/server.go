@@ -4,18 +4,31 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net"
 	"sync"
+	"time"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"url-shortener/internal/analytics"
 	"url-shortener/internal/service"
 	"url-shortener/internal/storage"
 )
 
+const (
+	defaultStatsDays   = 30
+	importBatchSize    = 500
+	maxImportConflicts = 10000
+)
+
 type urlShortenerServer struct {
 	srv *service.URLShortenerService
 	UnimplementedURLShortenerServer
@@ -36,39 +49,430 @@ func (s *urlShortenerServer) CreateShortURL(ctx context.Context, req *CreateShor
 	originalURL := req.OriginalUrl
 	customAlias := req.CustomAlias
 
-	shortURL, err := s.srv.CreateShortURL(ctx, originalURL, customAlias)
+	expiresAt, err := expirationTime(req)
+	if err != nil {
+		return nil, invalidArgumentError(err)
+	}
+	mode, err := dedupeMode(req)
+	if err != nil {
+		return nil, invalidArgumentError(err)
+	}
+
+	url, err := s.srv.CreateShortURL(ctx, originalURL, customAlias, expiresAt, mode)
 	if err != nil {
 		log.Printf("failed to create short url: %v", err)
-		if errors.Is(err, service.ErrURLNotFound) {
-			return nil, status.Error(codes.NotFound, "short_url not found")
+		return nil, createError(err)
+	}
+
+	resp := &CreateShortURLResponse{ShortUrl: url.ShortURL}
+	if !url.ExpiresAt.IsZero() {
+		resp.ExpiresAt = timestamppb.New(url.ExpiresAt)
+	}
+	return resp, nil
+}
+
+func (s *urlShortenerServer) CreateShortURLs(ctx context.Context, req *CreateShortURLsRequest) (*CreateShortURLsResponse, error) {
+	resp := &CreateShortURLsResponse{Results: make([]*CreateShortURLResult, len(req.Items))}
+
+	reqs := make([]service.CreateRequest, 0, len(req.Items))
+	reqIdx := make([]int, 0, len(req.Items))
+	for i, item := range req.Items {
+		expiresAt, err := expirationTime(item)
+		if err != nil {
+			resp.Results[i] = &CreateShortURLResult{Code: int32(codes.InvalidArgument), Message: err.Error()}
+			continue
 		}
-		if errors.Is(err, service.ErrURLExists) {
-			return nil, status.Error(codes.AlreadyExists, "url already exists")
+		mode, err := dedupeMode(item)
+		if err != nil {
+			resp.Results[i] = &CreateShortURLResult{Code: int32(codes.InvalidArgument), Message: err.Error()}
+			continue
 		}
-		if errors.Is(err, service.ErrAliasAlreadyExists) {
-			return nil, status.Error(codes.AlreadyExists, "custom alias already exists")
+		reqs = append(reqs, service.CreateRequest{
+			OriginalURL: item.OriginalUrl,
+			CustomAlias: item.CustomAlias,
+			ExpiresAt:   expiresAt,
+			DedupeMode:  mode,
+		})
+		reqIdx = append(reqIdx, i)
+	}
+
+	if len(reqs) == 0 && len(req.Items) > 0 {
+		return resp, nil
+	}
+
+	results, err := s.srv.CreateShortURLs(ctx, reqs)
+	if err != nil {
+		log.Printf("failed to create short urls: %v", err)
+		return nil, createError(err)
+	}
+
+	for j, result := range results {
+		i := reqIdx[j]
+		if result.Err != nil {
+			st := status.Convert(createError(result.Err))
+			resp.Results[i] = &CreateShortURLResult{Code: int32(st.Code()), Message: st.Message()}
+			continue
+		}
+		resp.Results[i] = &CreateShortURLResult{ShortUrl: result.URL.ShortURL}
+		if !result.URL.ExpiresAt.IsZero() {
+			resp.Results[i].ExpiresAt = timestamppb.New(result.URL.ExpiresAt)
+		}
+	}
+	return resp, nil
+}
+
+func (s *urlShortenerServer) ImportURLs(stream URLShortener_ImportURLsServer) error {
+	ctx := stream.Context()
+	resp := &ImportURLsResponse{}
+
+	var index int64
+	batch := make([]storage.URL, 0, importBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		results, err := s.srv.ImportURLs(ctx, batch)
+		if err != nil {
+			log.Printf("failed to import urls: %v", err)
+			return createError(err)
+		}
+
+		first := index - int64(len(batch))
+		for i, err := range results {
+			if err == nil {
+				resp.Imported++
+				continue
+			}
+			resp.ConflictCount++
+			if len(resp.Conflicts) >= maxImportConflicts {
+				continue
+			}
+			st := status.Convert(createError(err))
+			message := st.Message()
+			if errors.Is(err, service.ErrURLExists) {
+				message = err.Error()
+			}
+			resp.Conflicts = append(resp.Conflicts, &ImportConflict{
+				Index:       first + int64(i),
+				ShortUrl:    batch[i].ShortURL,
+				OriginalUrl: batch[i].OriginalURL,
+				Code:        int32(st.Code()),
+				Message:     message,
+			})
 		}
-		return nil, status.Error(codes.Internal, "internal error")
+		batch = batch[:0]
+		return nil
 	}
 
-	return &CreateShortURLResponse{ShortUrl: shortURL}, nil
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		url := storage.URL{ShortURL: req.ShortUrl, OriginalURL: req.OriginalUrl}
+		if req.ExpiresAt != nil {
+			url.ExpiresAt = req.ExpiresAt.AsTime()
+		}
+		if req.CreatedAt != nil {
+			url.CreatedAt = req.CreatedAt.AsTime()
+		}
+		batch = append(batch, url)
+		index++
+
+		if len(batch) == importBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		return err
+	}
+	return stream.SendAndClose(resp)
+}
+
+func (s *urlShortenerServer) ExportURLs(req *ExportURLsRequest, stream URLShortener_ExportURLsServer) error {
+	err := s.srv.ExportURLs(stream.Context(), func(url storage.URL) error {
+		return stream.Send(toProtoURL(url))
+	})
+	if err != nil {
+		log.Printf("failed to export urls: %v", err)
+		if errors.Is(err, service.ErrInternal) || errors.Is(err, service.ErrDeadlineExceeded) || errors.Is(err, service.ErrCanceled) {
+			return internalError(err)
+		}
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return status.FromContextError(err).Err()
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *urlShortenerServer) ListURLs(ctx context.Context, req *ListURLsRequest) (*ListURLsResponse, error) {
+	urls, next, err := s.srv.ListURLs(ctx, service.ListRequest{
+		PageSize:    int(req.PageSize),
+		PageToken:   req.PageToken,
+		AliasPrefix: req.AliasPrefix,
+		URLContains: req.OriginalUrlContains,
+		Descending:  req.Descending,
+	})
+	if err != nil {
+		log.Printf("failed to list urls: %v", err)
+		if errors.Is(err, service.ErrInvalidArgument) {
+			return nil, invalidArgumentError(err)
+		}
+		return nil, internalError(err)
+	}
+
+	resp := &ListURLsResponse{
+		Urls:          make([]*URL, 0, len(urls)),
+		NextPageToken: next,
+	}
+	for _, url := range urls {
+		resp.Urls = append(resp.Urls, toProtoURL(url))
+	}
+	return resp, nil
 }
 
 func (s *urlShortenerServer) GetOriginalURL(ctx context.Context, req *GetOriginalURLRequest) (*GetOriginalURLResponse, error) {
 	shortURL := req.ShortUrl
+	ctx = analytics.WithClientInfo(ctx, clientInfo(ctx))
 
 	originalURL, err := s.srv.GetOriginalURL(ctx, shortURL)
 	if err != nil {
 		log.Printf("failed to get original url: %v", err)
-		if errors.Is(err, storage.ErrURLNotFound) {
+		if errors.Is(err, service.ErrURLNotFound) {
 			return nil, status.Error(codes.NotFound, "short_url not found")
 		}
-		return nil, status.Error(codes.Internal, "internal error")
+		if errors.Is(err, service.ErrURLExpired) {
+			return nil, status.Error(codes.FailedPrecondition, "short_url has expired")
+		}
+		if errors.Is(err, service.ErrInvalidArgument) {
+			return nil, invalidArgumentError(err)
+		}
+		return nil, internalError(err)
 	}
 
 	return &GetOriginalURLResponse{OriginalUrl: originalURL}, nil
 }
 
+func (s *urlShortenerServer) LookupByOriginalURL(ctx context.Context, req *LookupByOriginalURLRequest) (*LookupByOriginalURLResponse, error) {
+	url, err := s.srv.LookupShortURL(ctx, req.OriginalUrl)
+	if err != nil {
+		if !errors.Is(err, service.ErrURLNotFound) {
+			log.Printf("failed to look up short url: %v", err)
+		}
+		return nil, lookupError(err)
+	}
+
+	return &LookupByOriginalURLResponse{Url: toProtoURL(url)}, nil
+}
+
+func (s *urlShortenerServer) LookupByOriginalURLs(ctx context.Context, req *LookupByOriginalURLsRequest) (*LookupByOriginalURLsResponse, error) {
+	results, err := s.srv.LookupShortURLs(ctx, req.OriginalUrls)
+	if err != nil {
+		log.Printf("failed to look up short urls: %v", err)
+		return nil, lookupError(err)
+	}
+
+	resp := &LookupByOriginalURLsResponse{Results: make([]*LookupByOriginalURLResult, len(results))}
+	for i, result := range results {
+		if result.Err != nil {
+			st := status.Convert(lookupError(result.Err))
+			resp.Results[i] = &LookupByOriginalURLResult{Code: int32(st.Code()), Message: st.Message()}
+			continue
+		}
+		resp.Results[i] = &LookupByOriginalURLResult{Url: toProtoURL(result.URL)}
+	}
+	return resp, nil
+}
+
+func (s *urlShortenerServer) DeleteShortURL(ctx context.Context, req *DeleteShortURLRequest) (*DeleteShortURLResponse, error) {
+	err := s.srv.DeleteShortURL(ctx, req.ShortUrl)
+	if err != nil {
+		log.Printf("failed to delete short url: %v", err)
+		if errors.Is(err, service.ErrURLNotFound) {
+			return nil, status.Error(codes.NotFound, "short_url not found")
+		}
+		if errors.Is(err, service.ErrInvalidArgument) {
+			return nil, invalidArgumentError(err)
+		}
+		return nil, internalError(err)
+	}
+
+	return &DeleteShortURLResponse{}, nil
+}
+
+func (s *urlShortenerServer) UpdateShortURL(ctx context.Context, req *UpdateShortURLRequest) (*UpdateShortURLResponse, error) {
+	url, err := s.srv.UpdateShortURL(ctx, req.ShortUrl, req.OriginalUrl)
+	if err != nil {
+		log.Printf("failed to update short url: %v", err)
+		if errors.Is(err, service.ErrURLNotFound) {
+			return nil, status.Error(codes.NotFound, "short_url not found")
+		}
+		if errors.Is(err, service.ErrURLExists) {
+			return nil, status.Error(codes.AlreadyExists, "url already exists")
+		}
+		if errors.Is(err, service.ErrInvalidArgument) {
+			return nil, invalidArgumentError(err)
+		}
+		return nil, internalError(err)
+	}
+
+	return &UpdateShortURLResponse{ShortUrl: url.ShortURL, OriginalUrl: url.OriginalURL}, nil
+}
+
+func (s *urlShortenerServer) GetURLStats(ctx context.Context, req *GetURLStatsRequest) (*GetURLStatsResponse, error) {
+	days := int(req.Days)
+	if days == 0 {
+		days = defaultStatsDays
+	}
+
+	stats, err := s.srv.GetURLStats(ctx, req.ShortUrl, days)
+	if err != nil {
+		log.Printf("failed to get url stats: %v", err)
+		if errors.Is(err, service.ErrURLNotFound) {
+			return nil, status.Error(codes.NotFound, "short_url not found")
+		}
+		if errors.Is(err, service.ErrAnalyticsDisabled) {
+			return nil, status.Error(codes.FailedPrecondition, "click analytics is disabled")
+		}
+		if errors.Is(err, service.ErrInvalidArgument) {
+			return nil, invalidArgumentError(err)
+		}
+		return nil, internalError(err)
+	}
+
+	resp := &GetURLStatsResponse{
+		ShortUrl:    req.ShortUrl,
+		TotalClicks: stats.Total,
+		DailyClicks: make([]*DailyClicks, 0, len(stats.Daily)),
+	}
+	for _, d := range stats.Daily {
+		resp.DailyClicks = append(resp.DailyClicks, &DailyClicks{
+			Date:   d.Day.Format(time.DateOnly),
+			Clicks: d.Clicks,
+		})
+	}
+	return resp, nil
+}
+
+func toProtoURL(url storage.URL) *URL {
+	u := &URL{
+		ShortUrl:    url.ShortURL,
+		OriginalUrl: url.OriginalURL,
+		CreatedAt:   timestamppb.New(url.CreatedAt),
+	}
+	if !url.ExpiresAt.IsZero() {
+		u.ExpiresAt = timestamppb.New(url.ExpiresAt)
+	}
+	return u
+}
+
+func expirationTime(req *CreateShortURLRequest) (time.Time, error) {
+	var expiresAt time.Time
+	if req.ExpiresAt != nil {
+		expiresAt = req.ExpiresAt.AsTime()
+	}
+	return service.ExpirationTime(req.Ttl.AsDuration(), expiresAt)
+}
+
+func dedupeMode(req *CreateShortURLRequest) (service.DedupeMode, error) {
+	switch req.DedupeMode {
+	case DedupeMode_DEDUPE_MODE_UNSPECIFIED, DedupeMode_DEDUPE_MODE_REUSE:
+		return service.DedupeReuse, nil
+	case DedupeMode_DEDUPE_MODE_ALWAYS_NEW:
+		return service.DedupeAlwaysNew, nil
+	case DedupeMode_DEDUPE_MODE_FAIL_IF_EXISTS:
+		return service.DedupeFailIfExists, nil
+	default:
+		return 0, &service.FieldError{Field: "dedupe_mode", Description: "is not a known mode"}
+	}
+}
+
+func createError(err error) error {
+	if errors.Is(err, service.ErrURLNotFound) {
+		return status.Error(codes.NotFound, "short_url not found")
+	}
+	if errors.Is(err, service.ErrURLExists) {
+		return status.Error(codes.AlreadyExists, "url already exists")
+	}
+	if errors.Is(err, service.ErrAliasAlreadyExists) {
+		return status.Error(codes.AlreadyExists, "custom alias already exists")
+	}
+	if errors.Is(err, service.ErrInvalidArgument) {
+		return invalidArgumentError(err)
+	}
+	if errors.Is(err, service.ErrKeyspaceExhausted) {
+		return status.Error(codes.ResourceExhausted, "no free short_url found, try again later")
+	}
+	return internalError(err)
+}
+
+func lookupError(err error) error {
+	if errors.Is(err, service.ErrURLNotFound) {
+		return status.Error(codes.NotFound, "original_url is not shortened")
+	}
+	if errors.Is(err, service.ErrInvalidArgument) {
+		return invalidArgumentError(err)
+	}
+	return internalError(err)
+}
+
+// invalidArgumentError reports an invalid request, with a field violation in
+// the details if the error names the offending field.
+func invalidArgumentError(err error) error {
+	st := status.New(codes.InvalidArgument, err.Error())
+	var fieldErr *service.FieldError
+	if errors.As(err, &fieldErr) {
+		detailed, detailsErr := st.WithDetails(&errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{
+				{Field: fieldErr.Field, Description: fieldErr.Description},
+			},
+		})
+		if detailsErr == nil {
+			st = detailed
+		}
+	}
+	return st.Err()
+}
+
+// internalError maps errors that have no more specific status, reporting
+// storage deadlines and cancellations with their own codes.
+func internalError(err error) error {
+	if errors.Is(err, service.ErrDeadlineExceeded) {
+		return status.Error(codes.DeadlineExceeded, "deadline exceeded")
+	}
+	if errors.Is(err, service.ErrCanceled) {
+		return status.Error(codes.Canceled, "request canceled")
+	}
+	if errors.Is(err, service.ErrUnavailable) {
+		return status.Error(codes.Unavailable, "storage unavailable")
+	}
+	return status.Error(codes.Internal, "internal error")
+}
+
+func clientInfo(ctx context.Context) analytics.ClientInfo {
+	var info analytics.ClientInfo
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		info.ClientAddress = p.Addr.String()
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get("referer"); len(v) > 0 {
+			info.Referrer = v[0]
+		}
+		if v := md.Get("user-agent"); len(v) > 0 {
+			info.UserAgent = v[0]
+		}
+	}
+	return info
+}
+
 func (s *urlShortenerServer) mustEmbedUnimplementedURLShortenerServer() {}
 
 func StartGRPCServer(grpcAddress string, urlService *service.URLShortenerService) error {
@@ -19,8 +19,17 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	URLShortener_CreateShortURL_FullMethodName = "/url_shortener.URLShortener/CreateShortURL"
-	URLShortener_GetOriginalURL_FullMethodName = "/url_shortener.URLShortener/GetOriginalURL"
+	URLShortener_CreateShortURL_FullMethodName       = "/url_shortener.URLShortener/CreateShortURL"
+	URLShortener_CreateShortURLs_FullMethodName      = "/url_shortener.URLShortener/CreateShortURLs"
+	URLShortener_ImportURLs_FullMethodName           = "/url_shortener.URLShortener/ImportURLs"
+	URLShortener_ExportURLs_FullMethodName           = "/url_shortener.URLShortener/ExportURLs"
+	URLShortener_ListURLs_FullMethodName             = "/url_shortener.URLShortener/ListURLs"
+	URLShortener_GetOriginalURL_FullMethodName       = "/url_shortener.URLShortener/GetOriginalURL"
+	URLShortener_LookupByOriginalURL_FullMethodName  = "/url_shortener.URLShortener/LookupByOriginalURL"
+	URLShortener_LookupByOriginalURLs_FullMethodName = "/url_shortener.URLShortener/LookupByOriginalURLs"
+	URLShortener_DeleteShortURL_FullMethodName       = "/url_shortener.URLShortener/DeleteShortURL"
+	URLShortener_UpdateShortURL_FullMethodName       = "/url_shortener.URLShortener/UpdateShortURL"
+	URLShortener_GetURLStats_FullMethodName          = "/url_shortener.URLShortener/GetURLStats"
 )
 
 // URLShortenerClient is the client API for URLShortener service.
@@ -29,8 +38,27 @@ const (
 type URLShortenerClient interface {
 	// Creates a short URL
 	CreateShortURL(ctx context.Context, in *CreateShortURLRequest, opts ...grpc.CallOption) (*CreateShortURLResponse, error)
+	// Creates many short URLs at once
+	CreateShortURLs(ctx context.Context, in *CreateShortURLsRequest, opts ...grpc.CallOption) (*CreateShortURLsResponse, error)
+	// Imports existing short URLs keeping their aliases
+	ImportURLs(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ImportURLsRequest, ImportURLsResponse], error)
+	// Streams every stored short URL ordered by creation time
+	ExportURLs(ctx context.Context, in *ExportURLsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[URL], error)
+	// Lists short URLs page by page ordered by creation time
+	ListURLs(ctx context.Context, in *ListURLsRequest, opts ...grpc.CallOption) (*ListURLsResponse, error)
 	// Gets the original URL by short URL
 	GetOriginalURL(ctx context.Context, in *GetOriginalURLRequest, opts ...grpc.CallOption) (*GetOriginalURLResponse, error)
+	// Finds the short URL an original URL would be shortened to, without
+	// creating one
+	LookupByOriginalURL(ctx context.Context, in *LookupByOriginalURLRequest, opts ...grpc.CallOption) (*LookupByOriginalURLResponse, error)
+	// Finds the short URLs of many original URLs at once
+	LookupByOriginalURLs(ctx context.Context, in *LookupByOriginalURLsRequest, opts ...grpc.CallOption) (*LookupByOriginalURLsResponse, error)
+	// Deletes a short URL
+	DeleteShortURL(ctx context.Context, in *DeleteShortURLRequest, opts ...grpc.CallOption) (*DeleteShortURLResponse, error)
+	// Changes the original URL a short URL points to
+	UpdateShortURL(ctx context.Context, in *UpdateShortURLRequest, opts ...grpc.CallOption) (*UpdateShortURLResponse, error)
+	// Gets click statistics of a short URL
+	GetURLStats(ctx context.Context, in *GetURLStatsRequest, opts ...grpc.CallOption) (*GetURLStatsResponse, error)
 }
 
 type uRLShortenerClient struct {
@@ -51,6 +79,58 @@ func (c *uRLShortenerClient) CreateShortURL(ctx context.Context, in *CreateShort
 	return out, nil
 }
 
+func (c *uRLShortenerClient) CreateShortURLs(ctx context.Context, in *CreateShortURLsRequest, opts ...grpc.CallOption) (*CreateShortURLsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateShortURLsResponse)
+	err := c.cc.Invoke(ctx, URLShortener_CreateShortURLs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLShortenerClient) ImportURLs(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ImportURLsRequest, ImportURLsResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &URLShortener_ServiceDesc.Streams[0], URLShortener_ImportURLs_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ImportURLsRequest, ImportURLsResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type URLShortener_ImportURLsClient = grpc.ClientStreamingClient[ImportURLsRequest, ImportURLsResponse]
+
+func (c *uRLShortenerClient) ExportURLs(ctx context.Context, in *ExportURLsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[URL], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &URLShortener_ServiceDesc.Streams[1], URLShortener_ExportURLs_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExportURLsRequest, URL]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type URLShortener_ExportURLsClient = grpc.ServerStreamingClient[URL]
+
+func (c *uRLShortenerClient) ListURLs(ctx context.Context, in *ListURLsRequest, opts ...grpc.CallOption) (*ListURLsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListURLsResponse)
+	err := c.cc.Invoke(ctx, URLShortener_ListURLs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *uRLShortenerClient) GetOriginalURL(ctx context.Context, in *GetOriginalURLRequest, opts ...grpc.CallOption) (*GetOriginalURLResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetOriginalURLResponse)
@@ -61,14 +141,83 @@ func (c *uRLShortenerClient) GetOriginalURL(ctx context.Context, in *GetOriginal
 	return out, nil
 }
 
+func (c *uRLShortenerClient) LookupByOriginalURL(ctx context.Context, in *LookupByOriginalURLRequest, opts ...grpc.CallOption) (*LookupByOriginalURLResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LookupByOriginalURLResponse)
+	err := c.cc.Invoke(ctx, URLShortener_LookupByOriginalURL_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLShortenerClient) LookupByOriginalURLs(ctx context.Context, in *LookupByOriginalURLsRequest, opts ...grpc.CallOption) (*LookupByOriginalURLsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LookupByOriginalURLsResponse)
+	err := c.cc.Invoke(ctx, URLShortener_LookupByOriginalURLs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLShortenerClient) DeleteShortURL(ctx context.Context, in *DeleteShortURLRequest, opts ...grpc.CallOption) (*DeleteShortURLResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteShortURLResponse)
+	err := c.cc.Invoke(ctx, URLShortener_DeleteShortURL_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLShortenerClient) UpdateShortURL(ctx context.Context, in *UpdateShortURLRequest, opts ...grpc.CallOption) (*UpdateShortURLResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateShortURLResponse)
+	err := c.cc.Invoke(ctx, URLShortener_UpdateShortURL_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLShortenerClient) GetURLStats(ctx context.Context, in *GetURLStatsRequest, opts ...grpc.CallOption) (*GetURLStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetURLStatsResponse)
+	err := c.cc.Invoke(ctx, URLShortener_GetURLStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // URLShortenerServer is the server API for URLShortener service.
 // All implementations must embed UnimplementedURLShortenerServer
 // for forward compatibility.
 type URLShortenerServer interface {
 	// Creates a short URL
 	CreateShortURL(context.Context, *CreateShortURLRequest) (*CreateShortURLResponse, error)
+	// Creates many short URLs at once
+	CreateShortURLs(context.Context, *CreateShortURLsRequest) (*CreateShortURLsResponse, error)
+	// Imports existing short URLs keeping their aliases
+	ImportURLs(grpc.ClientStreamingServer[ImportURLsRequest, ImportURLsResponse]) error
+	// Streams every stored short URL ordered by creation time
+	ExportURLs(*ExportURLsRequest, grpc.ServerStreamingServer[URL]) error
+	// Lists short URLs page by page ordered by creation time
+	ListURLs(context.Context, *ListURLsRequest) (*ListURLsResponse, error)
 	// Gets the original URL by short URL
 	GetOriginalURL(context.Context, *GetOriginalURLRequest) (*GetOriginalURLResponse, error)
+	// Finds the short URL an original URL would be shortened to, without
+	// creating one
+	LookupByOriginalURL(context.Context, *LookupByOriginalURLRequest) (*LookupByOriginalURLResponse, error)
+	// Finds the short URLs of many original URLs at once
+	LookupByOriginalURLs(context.Context, *LookupByOriginalURLsRequest) (*LookupByOriginalURLsResponse, error)
+	// Deletes a short URL
+	DeleteShortURL(context.Context, *DeleteShortURLRequest) (*DeleteShortURLResponse, error)
+	// Changes the original URL a short URL points to
+	UpdateShortURL(context.Context, *UpdateShortURLRequest) (*UpdateShortURLResponse, error)
+	// Gets click statistics of a short URL
+	GetURLStats(context.Context, *GetURLStatsRequest) (*GetURLStatsResponse, error)
 	mustEmbedUnimplementedURLShortenerServer()
 }
 
@@ -82,9 +231,36 @@ type UnimplementedURLShortenerServer struct{}
 func (UnimplementedURLShortenerServer) CreateShortURL(context.Context, *CreateShortURLRequest) (*CreateShortURLResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateShortURL not implemented")
 }
+func (UnimplementedURLShortenerServer) CreateShortURLs(context.Context, *CreateShortURLsRequest) (*CreateShortURLsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateShortURLs not implemented")
+}
+func (UnimplementedURLShortenerServer) ImportURLs(grpc.ClientStreamingServer[ImportURLsRequest, ImportURLsResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method ImportURLs not implemented")
+}
+func (UnimplementedURLShortenerServer) ExportURLs(*ExportURLsRequest, grpc.ServerStreamingServer[URL]) error {
+	return status.Errorf(codes.Unimplemented, "method ExportURLs not implemented")
+}
+func (UnimplementedURLShortenerServer) ListURLs(context.Context, *ListURLsRequest) (*ListURLsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListURLs not implemented")
+}
 func (UnimplementedURLShortenerServer) GetOriginalURL(context.Context, *GetOriginalURLRequest) (*GetOriginalURLResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetOriginalURL not implemented")
 }
+func (UnimplementedURLShortenerServer) LookupByOriginalURL(context.Context, *LookupByOriginalURLRequest) (*LookupByOriginalURLResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LookupByOriginalURL not implemented")
+}
+func (UnimplementedURLShortenerServer) LookupByOriginalURLs(context.Context, *LookupByOriginalURLsRequest) (*LookupByOriginalURLsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LookupByOriginalURLs not implemented")
+}
+func (UnimplementedURLShortenerServer) DeleteShortURL(context.Context, *DeleteShortURLRequest) (*DeleteShortURLResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteShortURL not implemented")
+}
+func (UnimplementedURLShortenerServer) UpdateShortURL(context.Context, *UpdateShortURLRequest) (*UpdateShortURLResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateShortURL not implemented")
+}
+func (UnimplementedURLShortenerServer) GetURLStats(context.Context, *GetURLStatsRequest) (*GetURLStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetURLStats not implemented")
+}
 func (UnimplementedURLShortenerServer) mustEmbedUnimplementedURLShortenerServer() {}
 func (UnimplementedURLShortenerServer) testEmbeddedByValue()                      {}
 
@@ -124,6 +300,60 @@ func _URLShortener_CreateShortURL_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _URLShortener_CreateShortURLs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateShortURLsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLShortenerServer).CreateShortURLs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLShortener_CreateShortURLs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLShortenerServer).CreateShortURLs(ctx, req.(*CreateShortURLsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLShortener_ImportURLs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(URLShortenerServer).ImportURLs(&grpc.GenericServerStream[ImportURLsRequest, ImportURLsResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type URLShortener_ImportURLsServer = grpc.ClientStreamingServer[ImportURLsRequest, ImportURLsResponse]
+
+func _URLShortener_ExportURLs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportURLsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(URLShortenerServer).ExportURLs(m, &grpc.GenericServerStream[ExportURLsRequest, URL]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type URLShortener_ExportURLsServer = grpc.ServerStreamingServer[URL]
+
+func _URLShortener_ListURLs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListURLsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLShortenerServer).ListURLs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLShortener_ListURLs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLShortenerServer).ListURLs(ctx, req.(*ListURLsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _URLShortener_GetOriginalURL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetOriginalURLRequest)
 	if err := dec(in); err != nil {
@@ -142,6 +372,96 @@ func _URLShortener_GetOriginalURL_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _URLShortener_LookupByOriginalURL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LookupByOriginalURLRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLShortenerServer).LookupByOriginalURL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLShortener_LookupByOriginalURL_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLShortenerServer).LookupByOriginalURL(ctx, req.(*LookupByOriginalURLRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLShortener_LookupByOriginalURLs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LookupByOriginalURLsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLShortenerServer).LookupByOriginalURLs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLShortener_LookupByOriginalURLs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLShortenerServer).LookupByOriginalURLs(ctx, req.(*LookupByOriginalURLsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLShortener_DeleteShortURL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteShortURLRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLShortenerServer).DeleteShortURL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLShortener_DeleteShortURL_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLShortenerServer).DeleteShortURL(ctx, req.(*DeleteShortURLRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLShortener_UpdateShortURL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateShortURLRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLShortenerServer).UpdateShortURL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLShortener_UpdateShortURL_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLShortenerServer).UpdateShortURL(ctx, req.(*UpdateShortURLRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLShortener_GetURLStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetURLStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLShortenerServer).GetURLStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLShortener_GetURLStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLShortenerServer).GetURLStats(ctx, req.(*GetURLStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // URLShortener_ServiceDesc is the grpc.ServiceDesc for URLShortener service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -153,11 +473,50 @@ var URLShortener_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CreateShortURL",
 			Handler:    _URLShortener_CreateShortURL_Handler,
 		},
+		{
+			MethodName: "CreateShortURLs",
+			Handler:    _URLShortener_CreateShortURLs_Handler,
+		},
+		{
+			MethodName: "ListURLs",
+			Handler:    _URLShortener_ListURLs_Handler,
+		},
 		{
 			MethodName: "GetOriginalURL",
 			Handler:    _URLShortener_GetOriginalURL_Handler,
 		},
+		{
+			MethodName: "LookupByOriginalURL",
+			Handler:    _URLShortener_LookupByOriginalURL_Handler,
+		},
+		{
+			MethodName: "LookupByOriginalURLs",
+			Handler:    _URLShortener_LookupByOriginalURLs_Handler,
+		},
+		{
+			MethodName: "DeleteShortURL",
+			Handler:    _URLShortener_DeleteShortURL_Handler,
+		},
+		{
+			MethodName: "UpdateShortURL",
+			Handler:    _URLShortener_UpdateShortURL_Handler,
+		},
+		{
+			MethodName: "GetURLStats",
+			Handler:    _URLShortener_GetURLStats_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ImportURLs",
+			Handler:       _URLShortener_ImportURLs_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "ExportURLs",
+			Handler:       _URLShortener_ExportURLs_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "internal/grpc/url_shortener.proto",
 }
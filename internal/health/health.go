@@ -0,0 +1,115 @@
+// Package health reports whether the service is ready to take requests. The
+// status is published by the standard grpc.health.v1 service and mirrored by
+// the HTTP probes of the admin server.
+package health
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	mygrpc "url-shortener/internal/grpc"
+	"url-shortener/internal/storage"
+)
+
+// Checker drives the serving status from periodic pings of the storage. The
+// status applies both to the whole server ("") and to the URLShortener
+// service.
+type Checker struct {
+	server  *health.Server
+	pinger  storage.Pinger // nil if the storage needs no ping
+	timeout time.Duration
+}
+
+// NewChecker returns a checker of s, which is NOT_SERVING until the first
+// check. Storages that do not implement storage.Pinger are always available.
+// Each ping is bounded by timeout unless it is zero.
+func NewChecker(s storage.URLSaverURLGetter, timeout time.Duration) *Checker {
+	pinger, _ := s.(storage.Pinger)
+	c := &Checker{
+		server:  health.NewServer(),
+		pinger:  pinger,
+		timeout: timeout,
+	}
+	c.setStatus(healthpb.HealthCheckResponse_NOT_SERVING)
+	return c
+}
+
+// Register registers the grpc.health.v1 service on s.
+func (c *Checker) Register(s *grpc.Server) {
+	healthpb.RegisterHealthServer(s, c.server)
+}
+
+// Run checks the storage right away and then every interval until ctx is done.
+func (c *Checker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		c.Check(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check pings the storage and updates the serving status. It does nothing
+// after Shutdown.
+func (c *Checker) Check(ctx context.Context) {
+	status := healthpb.HealthCheckResponse_SERVING
+	if c.pinger != nil {
+		if c.timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, c.timeout)
+			defer cancel()
+		}
+		if err := c.pinger.Ping(ctx); err != nil {
+			log.Printf("storage is unavailable: %v", err)
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+	}
+	c.setStatus(status)
+}
+
+// Shutdown switches to NOT_SERVING for good, so that clients stop sending
+// requests while the servers drain.
+func (c *Checker) Shutdown() {
+	c.server.Shutdown()
+}
+
+// Serving tells whether the server is ready to take requests.
+func (c *Checker) Serving() bool {
+	resp, err := c.server.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	return err == nil && resp.GetStatus() == healthpb.HealthCheckResponse_SERVING
+}
+
+// LivenessHandler answers 200 while the process is able to serve HTTP at all.
+func (c *Checker) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok\n"))
+	})
+}
+
+// ReadinessHandler answers 200 while the server is SERVING and 503 otherwise.
+func (c *Checker) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !c.Serving() {
+			http.Error(w, "not serving", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok\n"))
+	})
+}
+
+func (c *Checker) setStatus(status healthpb.HealthCheckResponse_ServingStatus) {
+	c.server.SetServingStatus("", status)
+	c.server.SetServingStatus(mygrpc.URLShortener_ServiceDesc.ServiceName, status)
+}
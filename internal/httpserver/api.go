@@ -0,0 +1,133 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"url-shortener/internal/service"
+)
+
+const maxRequestBodySize = 1 << 20
+
+type createURLRequest struct {
+	OriginalURL string     `json:"original_url"`
+	CustomAlias string     `json:"custom_alias,omitempty"`
+	TTLSeconds  int64      `json:"ttl_seconds,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	DedupeMode  string     `json:"dedupe_mode,omitempty"`
+}
+
+type createURLResponse struct {
+	ShortURL  string     `json:"short_url"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type getURLResponse struct {
+	ShortURL    string `json:"short_url"`
+	OriginalURL string `json:"original_url"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+	Field string `json:"field,omitempty"`
+}
+
+func (h *handler) registerAPI(mux *http.ServeMux) {
+	mux.HandleFunc("POST /api/v1/urls", h.createURL)
+	mux.HandleFunc("GET /api/v1/urls/{alias}", h.getURL)
+	mux.HandleFunc("GET /api/v1/openapi.json", h.openAPI)
+}
+
+func (h *handler) createURL(w http.ResponseWriter, r *http.Request) {
+	var req createURLRequest
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodySize)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid request body"})
+		return
+	}
+
+	var expiresAt time.Time
+	if req.ExpiresAt != nil {
+		expiresAt = *req.ExpiresAt
+	}
+	expiresAt, err := service.ExpirationTime(time.Duration(req.TTLSeconds)*time.Second, expiresAt)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	mode, err := service.ParseDedupeMode(req.DedupeMode)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	url, err := h.srv.CreateShortURL(r.Context(), req.OriginalURL, req.CustomAlias, expiresAt, mode)
+	if err != nil {
+		log.Printf("failed to create short url: %v", err)
+		writeServiceError(w, err)
+		return
+	}
+
+	resp := createURLResponse{ShortURL: url.ShortURL}
+	if !url.ExpiresAt.IsZero() {
+		resp.ExpiresAt = &url.ExpiresAt
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *handler) getURL(w http.ResponseWriter, r *http.Request) {
+	alias := r.PathValue("alias")
+
+	originalURL, err := h.srv.GetOriginalURL(withClientInfo(r), alias)
+	if err != nil {
+		log.Printf("failed to get original url: %v", err)
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, getURLResponse{ShortURL: alias, OriginalURL: originalURL})
+}
+
+func (h *handler) openAPI(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, OpenAPISpec())
+}
+
+// writeServiceError mirrors the status mapping of the gRPC server.
+func writeServiceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrURLNotFound):
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: "short_url not found"})
+	case errors.Is(err, service.ErrURLExpired):
+		writeJSON(w, http.StatusGone, errorResponse{Error: "short_url has expired"})
+	case errors.Is(err, service.ErrURLExists):
+		writeJSON(w, http.StatusConflict, errorResponse{Error: "url already exists"})
+	case errors.Is(err, service.ErrAliasAlreadyExists):
+		writeJSON(w, http.StatusConflict, errorResponse{Error: "custom alias already exists"})
+	case errors.Is(err, service.ErrInvalidArgument):
+		resp := errorResponse{Error: err.Error()}
+		var fieldErr *service.FieldError
+		if errors.As(err, &fieldErr) {
+			resp.Field = fieldErr.Field
+		}
+		writeJSON(w, http.StatusBadRequest, resp)
+	case errors.Is(err, service.ErrKeyspaceExhausted), errors.Is(err, service.ErrUnavailable):
+		writeJSON(w, http.StatusServiceUnavailable, errorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrDeadlineExceeded):
+		writeJSON(w, http.StatusGatewayTimeout, errorResponse{Error: err.Error()})
+	default:
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: "internal error"})
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("failed to write response: %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+package httpserver
+
+type object = map[string]any
+
+func errorResponseRef(description string) object {
+	return object{
+		"description": description,
+		"content": object{
+			"application/json": object{
+				"schema": object{"$ref": "#/components/schemas/Error"},
+			},
+		},
+	}
+}
+
+// OpenAPISpec describes the JSON API served under /api/v1.
+func OpenAPISpec() object {
+	return object{
+		"openapi": "3.0.3",
+		"info": object{
+			"title":   "URL Shortener API",
+			"version": "1.0.0",
+		},
+		"paths": object{
+			"/api/v1/urls": object{
+				"post": object{
+					"operationId": "createShortURL",
+					"summary":     "Creates a short URL",
+					"requestBody": object{
+						"required": true,
+						"content": object{
+							"application/json": object{
+								"schema": object{"$ref": "#/components/schemas/CreateShortURLRequest"},
+							},
+						},
+					},
+					"responses": object{
+						"200": object{
+							"description": "Short URL for the original URL",
+							"content": object{
+								"application/json": object{
+									"schema": object{"$ref": "#/components/schemas/CreateShortURLResponse"},
+								},
+							},
+						},
+						"400": errorResponseRef("Invalid request"),
+						"409": errorResponseRef("Custom alias or URL already exists"),
+						"500": errorResponseRef("Internal error"),
+						"503": errorResponseRef("Storage unavailable or alias keyspace exhausted"),
+						"504": errorResponseRef("Storage deadline exceeded"),
+					},
+				},
+			},
+			"/api/v1/urls/{alias}": object{
+				"get": object{
+					"operationId": "getOriginalURL",
+					"summary":     "Gets the original URL by short URL",
+					"parameters": []object{
+						{
+							"name":     "alias",
+							"in":       "path",
+							"required": true,
+							"schema":   object{"type": "string"},
+						},
+					},
+					"responses": object{
+						"200": object{
+							"description": "Original URL for the alias",
+							"content": object{
+								"application/json": object{
+									"schema": object{"$ref": "#/components/schemas/GetOriginalURLResponse"},
+								},
+							},
+						},
+						"400": errorResponseRef("Malformed alias or invalid check character"),
+						"404": errorResponseRef("Short URL not found"),
+						"410": errorResponseRef("Short URL has expired"),
+						"500": errorResponseRef("Internal error"),
+						"503": errorResponseRef("Storage unavailable"),
+						"504": errorResponseRef("Storage deadline exceeded"),
+					},
+				},
+			},
+		},
+		"components": object{
+			"schemas": object{
+				"CreateShortURLRequest": object{
+					"type":     "object",
+					"required": []string{"original_url"},
+					"properties": object{
+						"original_url": object{"type": "string"},
+						"custom_alias": object{"type": "string"},
+						"ttl_seconds":  object{"type": "integer", "format": "int64", "minimum": 1},
+						"expires_at":   object{"type": "string", "format": "date-time"},
+						"dedupe_mode": object{
+							"type":    "string",
+							"enum":    []string{"reuse", "always_new", "fail_if_exists"},
+							"default": "reuse",
+						},
+					},
+				},
+				"CreateShortURLResponse": object{
+					"type":     "object",
+					"required": []string{"short_url"},
+					"properties": object{
+						"short_url":  object{"type": "string"},
+						"expires_at": object{"type": "string", "format": "date-time"},
+					},
+				},
+				"GetOriginalURLResponse": object{
+					"type":     "object",
+					"required": []string{"short_url", "original_url"},
+					"properties": object{
+						"short_url":    object{"type": "string"},
+						"original_url": object{"type": "string"},
+					},
+				},
+				"Error": object{
+					"type":     "object",
+					"required": []string{"error"},
+					"properties": object{
+						"error": object{"type": "string"},
+						"field": object{
+							"type":        "string",
+							"description": "Request field that caused a 400 error, if any.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,57 @@
+package httpserver
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/service"
+)
+
+// TestOpenAPISpecErrorResponses checks that every status writeServiceError
+// produces for an operation is documented.
+func TestOpenAPISpecErrorResponses(t *testing.T) {
+	commonErrors := []error{
+		service.ErrInvalidArgument,
+		&service.FieldError{Field: "short_url", Description: "invalid check character"},
+		service.ErrUnavailable,
+		service.ErrDeadlineExceeded,
+		service.ErrCanceled,
+		service.ErrInternal,
+		errors.New("unexpected"),
+	}
+	createErrors := append([]error{
+		service.ErrURLExists,
+		service.ErrAliasAlreadyExists,
+		service.ErrKeyspaceExhausted,
+	}, commonErrors...)
+	lookupErrors := append([]error{
+		service.ErrURLNotFound,
+		service.ErrURLExpired,
+	}, commonErrors...)
+
+	operations := []struct {
+		path, method string
+		errs         []error
+	}{
+		{path: "/api/v1/urls", method: "post", errs: createErrors},
+		{path: "/api/v1/urls/{alias}", method: "get", errs: lookupErrors},
+	}
+
+	paths := OpenAPISpec()["paths"].(object)
+	for _, op := range operations {
+		operation, ok := paths[op.path].(object)[op.method].(object)
+		require.True(t, ok, "%s %s is not documented", op.method, op.path)
+		responses := operation["responses"].(object)
+
+		for _, err := range op.errs {
+			w := httptest.NewRecorder()
+			writeServiceError(w, err)
+			assert.Contains(t, responses, strconv.Itoa(w.Code), "%s %s: status of %v", op.method, op.path, err)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+
+	"url-shortener/internal/analytics"
+	"url-shortener/internal/config"
+	"url-shortener/internal/service"
+)
+
+var (
+	notFoundPage = template.Must(template.New("not_found").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Link not found</title></head>
+<body>
+<h1>404 Not Found</h1>
+<p>The short link <code>{{.}}</code> does not exist.</p>
+</body>
+</html>
+`))
+	expiredPage = template.Must(template.New("expired").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Link expired</title></head>
+<body>
+<h1>410 Gone</h1>
+<p>The short link <code>{{.}}</code> has expired.</p>
+</body>
+</html>
+`))
+	invalidPage = template.Must(template.New("invalid").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Invalid link</title></head>
+<body>
+<h1>400 Bad Request</h1>
+<p>The short link <code>{{.}}</code> is mistyped.</p>
+</body>
+</html>
+`))
+)
+
+type handler struct {
+	srv               *service.URLShortenerService
+	permanentRedirect bool
+}
+
+func NewHandler(srv *service.URLShortenerService, permanentRedirect bool) http.Handler {
+	h := &handler{
+		srv:               srv,
+		permanentRedirect: permanentRedirect,
+	}
+
+	mux := http.NewServeMux()
+	h.registerAPI(mux)
+	// GET patterns also match HEAD requests.
+	mux.HandleFunc("GET /{alias}", h.redirect)
+
+	return mux
+}
+
+func NewServer(cfg config.HTTPServer, srv *service.URLShortenerService) *http.Server {
+	return &http.Server{
+		Addr:         cfg.HTTPAddress,
+		Handler:      NewHandler(srv, cfg.PermanentRedirect),
+		ReadTimeout:  cfg.Timeout,
+		WriteTimeout: cfg.Timeout,
+		IdleTimeout:  cfg.IdleTimeout,
+	}
+}
+
+// withClientInfo returns the context of r with the caller details recorded in
+// click events.
+func withClientInfo(r *http.Request) context.Context {
+	return analytics.WithClientInfo(r.Context(), analytics.ClientInfo{
+		Referrer:      r.Referer(),
+		UserAgent:     r.UserAgent(),
+		ClientAddress: r.RemoteAddr,
+	})
+}
+
+func (h *handler) redirect(w http.ResponseWriter, r *http.Request) {
+	alias := r.PathValue("alias")
+
+	originalURL, err := h.srv.GetOriginalURL(withClientInfo(r), alias)
+	if err != nil {
+		if errors.Is(err, service.ErrURLNotFound) {
+			renderPage(w, http.StatusNotFound, notFoundPage, alias)
+			return
+		}
+		if errors.Is(err, service.ErrURLExpired) {
+			renderPage(w, http.StatusGone, expiredPage, alias)
+			return
+		}
+		if errors.Is(err, service.ErrInvalidArgument) {
+			renderPage(w, http.StatusBadRequest, invalidPage, alias)
+			return
+		}
+		log.Printf("failed to resolve alias %q: %v", alias, err)
+		if errors.Is(err, service.ErrDeadlineExceeded) {
+			http.Error(w, "deadline exceeded", http.StatusGatewayTimeout)
+			return
+		}
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	code := http.StatusFound
+	if h.permanentRedirect {
+		code = http.StatusMovedPermanently
+	}
+
+	w.Header().Set("Location", originalURL)
+	w.WriteHeader(code)
+}
+
+func renderPage(w http.ResponseWriter, code int, page *template.Template, alias string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(code)
+	if err := page.Execute(w, alias); err != nil {
+		log.Printf("failed to render %s page: %v", page.Name(), err)
+	}
+}
@@ -8,13 +8,19 @@ import (
 const alphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_"
 
 func NewRandomString(length int) string {
+	return NewString(alphabet, length)
+}
+
+// NewString returns a random string of the given length made of the characters
+// of chars.
+func NewString(chars string, length int) string {
 	b := make([]byte, length)
 	for i := range b {
-		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(alphabet))))
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(chars))))
 		if err != nil {
 			panic(err)
 		}
-		b[i] = alphabet[n.Int64()]
+		b[i] = chars[n.Int64()]
 	}
 	return string(b)
 }
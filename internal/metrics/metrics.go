@@ -0,0 +1,16 @@
+// Package metrics exposes Prometheus metrics of the gRPC server and the URL
+// storage. Metrics are registered with the default registry and served by
+// Handler on the admin HTTP server.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Handler serves all registered metrics, including the Go runtime and process
+// metrics, in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"url-shortener/internal/storage"
+)
+
+var storageSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "storage_operation_seconds",
+	Help:    "Duration of storage operations by backend, operation and result.",
+	Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+}, []string{"backend", "operation", "result"})
+
+// Storage measures the latency of the hot storage operations. Every other
+// operation goes to the embedded storage as is.
+type Storage struct {
+	storage.URLSaverURLGetter
+	backend string
+}
+
+// NewStorage wraps s, labelling its metrics with the backend name.
+func NewStorage(backend string, s storage.URLSaverURLGetter) *Storage {
+	return &Storage{URLSaverURLGetter: s, backend: backend}
+}
+
+func (s *Storage) SaveURL(ctx context.Context, url storage.URL) (storage.URL, error) {
+	start := time.Now()
+	saved, err := s.URLSaverURLGetter.SaveURL(ctx, url)
+	s.observe("save_url", start, err)
+	return saved, err
+}
+
+func (s *Storage) GetURL(ctx context.Context, alias string) (string, error) {
+	start := time.Now()
+	originalURL, err := s.URLSaverURLGetter.GetURL(ctx, alias)
+	s.observe("get_url", start, err)
+	return originalURL, err
+}
+
+func (s *Storage) GetShortURL(ctx context.Context, canonicalURL string) (storage.URL, error) {
+	start := time.Now()
+	url, err := s.URLSaverURLGetter.GetShortURL(ctx, canonicalURL)
+	s.observe("get_short_url", start, err)
+	return url, err
+}
+
+// BackfillCanonicalURLs keeps the embedded storage's optional backfill
+// reachable through the wrapper.
+func (s *Storage) BackfillCanonicalURLs(ctx context.Context, canonicalize func(originalURL string) (string, error)) (int, error) {
+	backfiller, ok := s.URLSaverURLGetter.(storage.CanonicalBackfiller)
+	if !ok {
+		return 0, nil
+	}
+	return backfiller.BackfillCanonicalURLs(ctx, canonicalize)
+}
+
+func (s *Storage) observe(operation string, start time.Time, err error) {
+	storageSeconds.WithLabelValues(s.backend, operation, result(err)).Observe(time.Since(start).Seconds())
+}
+
+// result tells expected outcomes, such as a missing alias or a conflict, from
+// failures of the storage itself.
+func result(err error) string {
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.Is(err, storage.ErrURLNotFound), errors.Is(err, storage.ErrURLExpired):
+		return "not_found"
+	case errors.Is(err, storage.ErrAliasExists), errors.Is(err, storage.ErrOriginalURLExists):
+		return "conflict"
+	default:
+		return "error"
+	}
+}
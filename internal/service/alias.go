@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// AliasGenerator produces aliases for new short URLs. The implementations live
+// in the alias package.
+type AliasGenerator interface {
+	// Generate returns an alias for originalURL of about length characters.
+	// attempt is the number of preceding tries for the same short URL, which
+	// lets deterministic generators derive another alias after a collision.
+	Generate(ctx context.Context, originalURL string, length int, attempt int) (string, error)
+}
+
+// collisionRateWeight is the weight of the latest insert in the moving
+// collision rate, which roughly averages the last 20 inserts.
+const collisionRateWeight = 0.05
+
+// Alias generation metrics.
+var (
+	aliasInserts = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "alias_inserts_total",
+		Help: "Number of inserts of generated aliases.",
+	})
+	aliasCollisions = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "alias_collisions_total",
+		Help: "Number of inserts of generated aliases that hit a taken alias.",
+	})
+	aliasCollisionRate = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "alias_collision_rate",
+		Help: "Moving collision rate of the inserts of generated aliases.",
+	})
+	aliasLengthVar = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "alias_length",
+		Help: "Current length of generated aliases.",
+	})
+)
+
+// aliasLength holds the length of generated aliases. It tracks the moving
+// collision rate of inserts and, if growing is enabled, adds a character once
+// the rate exceeds the threshold.
+type aliasLength struct {
+	mu        sync.Mutex
+	length    int
+	maxLength int // growing is disabled if not greater than length
+	threshold float64
+	rate      float64
+}
+
+func newAliasLength(length int) *aliasLength {
+	aliasLengthVar.Set(float64(length))
+	return &aliasLength{length: length}
+}
+
+func (l *aliasLength) get() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.length
+}
+
+// observe records the outcome of inserting a generated alias of length n.
+func (l *aliasLength) observe(n int, collided bool) {
+	aliasInserts.Inc()
+	var sample float64
+	if collided {
+		aliasCollisions.Inc()
+		sample = 1
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if n != l.length {
+		// The alias was generated before the length grew.
+		return
+	}
+	l.rate += collisionRateWeight * (sample - l.rate)
+	if l.length < l.maxLength && l.rate > l.threshold {
+		l.length++
+		l.rate = 0
+		aliasLengthVar.Set(float64(l.length))
+		log.Printf("alias collision rate exceeded %.2f, generating aliases of %d characters", l.threshold, l.length)
+	}
+	aliasCollisionRate.Set(l.rate)
+}
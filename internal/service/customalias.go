@@ -0,0 +1,81 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"url-shortener/internal/alias"
+)
+
+// defaultAliasCharacters are the characters allowed in custom aliases unless
+// the policy says otherwise.
+var defaultAliasCharacters = alias.MustParseAlphabet(alias.DefaultAlphabet + "-")
+
+// AliasCase tells how the letter case of custom aliases is treated.
+type AliasCase int
+
+const (
+	// AliasCasePreserve keeps custom aliases as they are.
+	AliasCasePreserve AliasCase = iota
+	// AliasCaseLower converts custom aliases to lower case.
+	AliasCaseLower
+	// AliasCaseRejectUpper rejects custom aliases with upper case letters.
+	AliasCaseRejectUpper
+)
+
+// AliasPolicy restricts client supplied custom aliases.
+type AliasPolicy struct {
+	// MinLength and MaxLength bound the length of a custom alias. Zero
+	// values mean 1 and 64.
+	MinLength int
+	MaxLength int
+	// Characters defaults to letters, digits, "-" and "_".
+	Characters *alias.Alphabet
+	Case       AliasCase
+	// Reserved words, such as the first segments of HTTP routes, are
+	// compared case-insensitively.
+	Reserved []string
+}
+
+// WithAliasPolicy replaces the default policy for custom aliases.
+func WithAliasPolicy(policy AliasPolicy) Option {
+	return func(s *URLShortenerService) {
+		s.aliasPolicy = policy
+	}
+}
+
+// normalizeAlias validates the custom alias given in field and returns it with
+// the case policy applied. Errors are *FieldError.
+func (p AliasPolicy) normalizeAlias(field string, customAlias string) (string, error) {
+	minLength, maxLength := max(p.MinLength, 1), p.MaxLength
+	if maxLength == 0 {
+		maxLength = 64
+	}
+	if len(customAlias) < minLength || len(customAlias) > maxLength {
+		return "", invalidField(field, fmt.Sprintf("must be %d to %d characters long", minLength, maxLength))
+	}
+
+	switch p.Case {
+	case AliasCaseLower:
+		customAlias = strings.ToLower(customAlias)
+	case AliasCaseRejectUpper:
+		if customAlias != strings.ToLower(customAlias) {
+			return "", invalidField(field, "must not contain upper case letters")
+		}
+	}
+
+	characters := p.Characters
+	if characters == nil {
+		characters = defaultAliasCharacters
+	}
+	if !characters.Contains(customAlias) {
+		return "", invalidField(field, fmt.Sprintf("must consist of the characters %q", characters.String()))
+	}
+
+	for _, word := range p.Reserved {
+		if strings.EqualFold(customAlias, word) {
+			return "", invalidField(field, "is reserved")
+		}
+	}
+	return customAlias, nil
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"net"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/idna"
+
+	"url-shortener/internal/storage"
+)
+
+var defaultSchemes = []string{"http", "https"}
+
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// URLPolicy controls which original URLs are accepted and how their canonical
+// form is computed. Original URLs that share a canonical form share a short URL.
+type URLPolicy struct {
+	// AllowedSchemes defaults to http and https.
+	AllowedSchemes []string
+	// StripTrailingSlash removes trailing slashes of the path, so that
+	// "https://example.com/" becomes "https://example.com".
+	StripTrailingSlash bool
+	// SortQuery orders query parameters by name, keeping the order of
+	// repeated parameters.
+	SortQuery bool
+}
+
+// WithURLPolicy replaces the default policy for original URLs.
+func WithURLPolicy(policy URLPolicy) Option {
+	return func(s *URLShortenerService) {
+		s.urlPolicy = policy
+	}
+}
+
+// normalizeURL validates the original URL given in field and returns it as
+// submitted, minus surrounding spaces, along with its canonical form. Errors
+// are *FieldError.
+func (p URLPolicy) normalizeURL(field string, raw string) (storage.URL, error) {
+	raw = strings.TrimSpace(raw)
+	canonical, err := p.canonicalURL(field, raw)
+	if err != nil {
+		return storage.URL{}, err
+	}
+	return storage.URL{OriginalURL: raw, CanonicalURL: canonical}, nil
+}
+
+// canonicalURL returns the canonical form of an original URL: lowercase scheme
+// and host, punycode host and no default port.
+func (p URLPolicy) canonicalURL(field string, raw string) (string, error) {
+	if raw == "" {
+		return "", invalidField(field, "is required")
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", invalidField(field, "is not a valid url")
+	}
+	if u.Scheme == "" {
+		return "", invalidField(field, "must be an absolute url with a scheme")
+	}
+	schemes := p.AllowedSchemes
+	if len(schemes) == 0 {
+		schemes = defaultSchemes
+	}
+	if !slices.Contains(schemes, u.Scheme) {
+		return "", invalidField(field, "must use one of the schemes "+strings.Join(schemes, ", "))
+	}
+	if u.Opaque != "" || u.Host == "" {
+		return "", invalidField(field, "must have a host")
+	}
+
+	host, err := normalizeHost(u.Hostname())
+	if err != nil {
+		return "", invalidField(field, "has an invalid host")
+	}
+	port := u.Port()
+	if port != "" {
+		if n, err := strconv.Atoi(port); err != nil || n > 65535 {
+			return "", invalidField(field, "has an invalid port")
+		}
+		if port == defaultPorts[u.Scheme] {
+			port = ""
+		}
+	}
+	u.Host = host
+	if strings.Contains(host, ":") {
+		u.Host = "[" + host + "]"
+	}
+	if port != "" {
+		u.Host += ":" + port
+	}
+
+	if p.StripTrailingSlash {
+		u.Path = strings.TrimRight(u.Path, "/")
+		u.RawPath = strings.TrimRight(u.RawPath, "/")
+	}
+	if p.SortQuery {
+		u.RawQuery = sortQuery(u.RawQuery)
+	}
+
+	return u.String(), nil
+}
+
+// normalizeHost lowercases host and converts an internationalized domain name
+// to punycode. IP addresses are kept as they are.
+func normalizeHost(host string) (string, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.String(), nil
+	}
+	return idna.Lookup.ToASCII(strings.ToLower(host))
+}
+
+// sortQuery sorts the parameters of a raw query by name without re-encoding
+// them.
+func sortQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+	params := strings.Split(rawQuery, "&")
+	slices.SortStableFunc(params, func(a, b string) int {
+		a, _, _ = strings.Cut(a, "=")
+		b, _, _ = strings.Cut(b, "=")
+		return strings.Compare(a, b)
+	})
+	return strings.Join(params, "&")
+}
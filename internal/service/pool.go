@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/attribute"
+
+	"url-shortener/internal/storage"
+)
+
+// poolBatchSize is the number of aliases generated and stored at once while
+// refilling the pool.
+const poolBatchSize = 500
+
+// aliasPoolDepth is the last known number of pooled aliases.
+var aliasPoolDepth = promauto.NewGauge(prometheus.GaugeOpts{
+	Name: "alias_pool_depth",
+	Help: "Last known number of pre-generated aliases in the pool.",
+})
+
+// aliasPool hands out pre-generated aliases, so that creating a short URL does
+// not wait for alias generation. It is refilled in the background once its
+// depth drops below the watermark.
+type aliasPool struct {
+	store     storage.AliasPool
+	size      int
+	watermark int
+	depth     atomic.Int64
+	refill    chan struct{}
+}
+
+// WithAliasPool makes CreateShortURL take aliases from store, which is kept
+// filled with up to size aliases by RunAliasPool. Aliases are generated anew
+// while the pool is empty.
+func WithAliasPool(store storage.AliasPool, size int, watermark int) Option {
+	return func(s *URLShortenerService) {
+		s.pool = &aliasPool{
+			store:     store,
+			size:      size,
+			watermark: watermark,
+			refill:    make(chan struct{}, 1),
+		}
+	}
+}
+
+// takePooledAliases takes up to n aliases from the pool. A failure to take
+// them is not fatal, as the caller generates the aliases itself.
+func (s *URLShortenerService) takePooledAliases(ctx context.Context, n int) []string {
+	if s.pool == nil || n == 0 {
+		return nil
+	}
+
+	ctx, span := startSpan(ctx, "takePooledAliases", attribute.Int("alias.count", n))
+	aliases, err := s.pool.store.TakePoolAliases(ctx, n)
+	endSpan(span, err)
+	if err != nil {
+		log.Printf("failed to take pooled aliases: %v", err)
+		return nil
+	}
+
+	depth := s.pool.depth.Add(-int64(len(aliases)))
+	aliasPoolDepth.Set(float64(max(depth, 0)))
+	if len(aliases) < n || depth < int64(s.pool.watermark) {
+		select {
+		case s.pool.refill <- struct{}{}:
+		default:
+		}
+	}
+	return aliases
+}
+
+// returnPooledAliases puts taken aliases that ended up unused back into the
+// pool. Losing them is not fatal, as the pool is refilled anyway.
+func (s *URLShortenerService) returnPooledAliases(ctx context.Context, aliases []string) {
+	if s.pool == nil || len(aliases) == 0 {
+		return
+	}
+
+	// The aliases are returned even if the request is canceled.
+	added, err := s.pool.store.AddPoolAliases(context.WithoutCancel(ctx), aliases)
+	if err != nil {
+		log.Printf("failed to return %d aliases to the pool: %v", len(aliases), err)
+		return
+	}
+	depth := s.pool.depth.Add(int64(added))
+	aliasPoolDepth.Set(float64(max(depth, 0)))
+}
+
+// RunAliasPool refills the alias pool every interval, or sooner when it runs
+// low, until ctx is done. It does nothing if the pool is disabled.
+func (s *URLShortenerService) RunAliasPool(ctx context.Context, interval time.Duration) {
+	if s.pool == nil {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.FillAliasPool(ctx); err != nil {
+			log.Printf("failed to fill alias pool: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.pool.refill:
+		}
+	}
+}
+
+// FillAliasPool tops the pool up to its size if its depth is below the
+// watermark and returns the number of added aliases.
+func (s *URLShortenerService) FillAliasPool(ctx context.Context) (int, error) {
+	depth, err := s.pool.store.PoolSize(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get alias pool size: %w", err)
+	}
+	s.setPoolDepth(depth)
+	if depth >= s.pool.watermark {
+		return 0, nil
+	}
+
+	var filled int
+	for missing := s.pool.size - depth; missing > 0; {
+		aliases := make([]string, 0, min(missing, poolBatchSize))
+		length := s.aliasLength.get()
+		for len(aliases) < cap(aliases) {
+			alias, err := s.generator.Generate(ctx, "", length, 0)
+			if err != nil {
+				return filled, fmt.Errorf("failed to generate alias: %w", err)
+			}
+			aliases = append(aliases, s.appendCheckCharacter(alias))
+		}
+
+		added, err := s.pool.store.AddPoolAliases(ctx, aliases)
+		if err != nil {
+			return filled, fmt.Errorf("failed to add pool aliases: %w", err)
+		}
+		if added == 0 {
+			// Every generated alias is taken: the keyspace is too crowded
+			// for pre-generation.
+			break
+		}
+		filled += added
+		missing -= added
+		s.setPoolDepth(depth + filled)
+	}
+
+	if filled > 0 {
+		log.Printf("added %d aliases to the pool", filled)
+	}
+	return filled, nil
+}
+
+func (s *URLShortenerService) setPoolDepth(depth int) {
+	s.pool.depth.Store(int64(depth))
+	aliasPoolDepth.Set(float64(depth))
+}
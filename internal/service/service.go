@@ -2,126 +2,937 @@ package service
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
+	"strings"
+	"time"
 
-	"url-shortener/internal/lib/random"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"url-shortener/internal/alias"
+	"url-shortener/internal/analytics"
 	"url-shortener/internal/storage"
 )
 
 var (
 	ErrURLNotFound        = errors.New("url not found")
+	ErrURLExpired         = errors.New("url expired")
 	ErrURLExists          = errors.New("url already exists")
 	ErrAliasAlreadyExists = errors.New("custom alias already exists")
+	ErrInvalidArgument    = errors.New("invalid argument")
+	ErrAnalyticsDisabled  = errors.New("click analytics is disabled")
 	ErrInternal           = errors.New("internal error")
+	ErrDeadlineExceeded   = errors.New("deadline exceeded")
+	ErrCanceled           = errors.New("request canceled")
+	// ErrKeyspaceExhausted means no free alias was found within the allowed
+	// number of attempts.
+	ErrKeyspaceExhausted = errors.New("alias keyspace exhausted")
+	ErrUnavailable       = errors.New("storage unavailable")
+)
+
+// FieldError is an ErrInvalidArgument error caused by a single request field.
+type FieldError struct {
+	Field       string
+	Description string
+}
+
+func invalidField(field string, description string) error {
+	return &FieldError{Field: field, Description: description}
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%v: %s %s", ErrInvalidArgument, e.Field, e.Description)
+}
+
+func (e *FieldError) Unwrap() error {
+	return ErrInvalidArgument
+}
+
+const (
+	defaultMaxBatchSize = 1000
+	defaultPageSize     = 50
+	maxPageSize         = 1000
+	defaultMaxAttempts  = 10
+	// maxStatsDays bounds the click histogram, which has an entry per day.
+	maxStatsDays = 366
 )
 
 type URLShortenerService struct {
-	storage        storage.URLSaverURLGetter
-	shortURLLength int
+	storage         storage.URLSaverURLGetter
+	generator       AliasGenerator
+	check           *alias.Alphabet // nil if aliases carry no check character
+	pool            *aliasPool
+	urlPolicy       URLPolicy
+	aliasPolicy     AliasPolicy
+	aliasLength     *aliasLength
+	maxAttempts     int
+	allowAliasReuse bool
+	clicks          *analytics.Recorder
+	maxBatchSize    int
+}
+
+// DedupeMode tells what to do when the original URL is already shortened.
+type DedupeMode int
+
+const (
+	// DedupeReuse returns the existing short URL.
+	DedupeReuse DedupeMode = iota
+	// DedupeAlwaysNew creates another short URL for the original URL.
+	DedupeAlwaysNew
+	// DedupeFailIfExists fails with ErrURLExists.
+	DedupeFailIfExists
+)
+
+// ParseDedupeMode returns the mode named "reuse", "always_new" or
+// "fail_if_exists". An empty name means DedupeReuse.
+func ParseDedupeMode(name string) (DedupeMode, error) {
+	switch name {
+	case "", "reuse":
+		return DedupeReuse, nil
+	case "always_new":
+		return DedupeAlwaysNew, nil
+	case "fail_if_exists":
+		return DedupeFailIfExists, nil
+	default:
+		return 0, invalidField("dedupe_mode", "must be one of reuse, always_new, fail_if_exists")
+	}
+}
+
+type CreateRequest struct {
+	OriginalURL string
+	CustomAlias string
+	ExpiresAt   time.Time
+	DedupeMode  DedupeMode
+}
+
+type CreateResult struct {
+	URL storage.URL
+	Err error
+}
+
+type LookupResult struct {
+	URL storage.URL
+	Err error
+}
+
+type ListRequest struct {
+	PageSize    int
+	PageToken   string
+	AliasPrefix string
+	URLContains string
+	Descending  bool // newest first
+}
+
+// pageToken is the position of the last URL of a page. It is handed to clients
+// as an opaque base64 string.
+type pageToken struct {
+	CreatedAt  int64  `json:"t"`
+	ShortURL   string `json:"a"`
+	Descending bool   `json:"d"`
+}
+
+type Option func(*URLShortenerService)
+
+// WithAliasReuse controls whether the alias of a deleted short URL may be
+// handed out again. By default deleted aliases are retired for good so that
+// an old link never silently starts pointing somewhere else.
+func WithAliasReuse(allow bool) Option {
+	return func(s *URLShortenerService) {
+		s.allowAliasReuse = allow
+	}
+}
+
+// WithClickRecorder enables click analytics: every resolved short URL is
+// reported to the recorder.
+func WithClickRecorder(recorder *analytics.Recorder) Option {
+	return func(s *URLShortenerService) {
+		s.clicks = recorder
+	}
+}
+
+func WithMaxBatchSize(size int) Option {
+	return func(s *URLShortenerService) {
+		s.maxBatchSize = size
+	}
+}
+
+// WithAliasGenerator replaces the default random alias generator.
+func WithAliasGenerator(generator AliasGenerator) Option {
+	return func(s *URLShortenerService) {
+		s.generator = generator
+	}
 }
 
-func NewURLShortenerService(storage storage.URLSaverURLGetter, shortURLLength int) *URLShortenerService {
-	return &URLShortenerService{
-		storage:        storage,
-		shortURLLength: shortURLLength,
+// WithCheckCharacter appends a check character of alphabet to every generated
+// alias, so that mistyped aliases are told apart from unknown ones. Custom
+// aliases must end with a valid check character too.
+//
+// Lookups still go to storage first and only an alias that is not found is
+// rejected for an invalid check character, as aliases stored before check
+// characters were enabled have none. For the same reason imported aliases are
+// accepted without one. Checking before the lookup would break those aliases.
+func WithCheckCharacter(alphabet *alias.Alphabet) Option {
+	return func(s *URLShortenerService) {
+		s.check = alphabet
 	}
 }
 
-func (s *URLShortenerService) CreateShortURL(ctx context.Context, originalURL string, customAlias string) (string, error) {
-	if originalURL == "" {
-		return "", errors.New("original_url is required")
+// WithMaxAttempts sets how many inserts of a new short URL are attempted before
+// giving up on alias collisions or storage failures.
+func WithMaxAttempts(attempts int) Option {
+	return func(s *URLShortenerService) {
+		s.maxAttempts = attempts
 	}
+}
+
+// WithAutoGrow makes generated aliases one character longer, up to maxLength,
+// whenever the moving collision rate exceeds threshold. The grown length is
+// not persisted and starts over from the configured one on restart.
+func WithAutoGrow(threshold float64, maxLength int) Option {
+	return func(s *URLShortenerService) {
+		s.aliasLength.threshold = threshold
+		s.aliasLength.maxLength = maxLength
+	}
+}
 
-	shortURL, err := s.getExistingShortURL(ctx, originalURL)
-	if err == nil {
-		return shortURL, nil
+func NewURLShortenerService(storage storage.URLSaverURLGetter, shortURLLength int, opts ...Option) *URLShortenerService {
+	s := &URLShortenerService{
+		storage:      storage,
+		generator:    alias.NewRandom(alias.MustParseAlphabet(alias.DefaultAlphabet)),
+		aliasLength:  newAliasLength(shortURLLength),
+		maxAttempts:  defaultMaxAttempts,
+		maxBatchSize: defaultMaxBatchSize,
 	}
-	if !errors.Is(err, ErrURLNotFound) {
-		log.Printf("failed to get existing short URL: %v", err)
-		return "", ErrInternal
+	for _, opt := range opts {
+		opt(s)
 	}
+	s.maxAttempts = max(s.maxAttempts, 1)
+	return s
+}
+
+// CreateShortURL returns the short URL of originalURL. Whether an existing
+// short URL of an original URL with the same canonical form is returned
+// depends on mode.
+func (s *URLShortenerService) CreateShortURL(ctx context.Context, originalURL string, customAlias string, expiresAt time.Time, mode DedupeMode) (_ storage.URL, err error) {
+	ctx, span := startSpan(ctx, "CreateShortURL")
+	defer func() { endSpan(span, err) }()
 
+	submitted, err := s.urlPolicy.normalizeURL("original_url", originalURL)
+	if err != nil {
+		return storage.URL{}, err
+	}
+	submitted.ExpiresAt = expiresAt
+	if !expiresAt.IsZero() && !expiresAt.After(time.Now()) {
+		return storage.URL{}, fmt.Errorf("%w: expires_at must be in the future", ErrInvalidArgument)
+	}
 	if customAlias != "" {
-		_, err := s.storage.GetURL(customAlias)
+		if customAlias, err = s.aliasPolicy.normalizeAlias("custom_alias", customAlias); err != nil {
+			return storage.URL{}, err
+		}
+		if err := s.checkAlias("custom_alias", customAlias); err != nil {
+			return storage.URL{}, err
+		}
+	}
+
+	// Random aliases are retried on collisions. Storage failures are retried
+	// as well, unless the request itself is canceled or timed out.
+	lastErr := ErrInternal
+	for attempt := 0; attempt < s.maxAttempts; attempt++ {
+		shortURL, length := customAlias, 0
+		var pooledAlias string
+		if shortURL == "" {
+			if pooled := s.takePooledAliases(ctx, 1); len(pooled) == 1 {
+				shortURL, pooledAlias = pooled[0], pooled[0]
+			}
+		}
+		if shortURL == "" {
+			length = s.aliasLength.get()
+			genCtx, span := startSpan(ctx, "generateAlias",
+				attribute.Int("alias.attempt", attempt), attribute.Int("alias.length", length))
+			generated, err := s.generator.Generate(genCtx, submitted.CanonicalURL, length, attempt)
+			endSpan(span, err)
+			if err != nil {
+				log.Printf("failed to generate alias: %v", err)
+				if err := storageError(err); err != ErrInternal {
+					return storage.URL{}, err
+				}
+				lastErr = ErrUnavailable
+				continue
+			}
+			shortURL = s.appendCheckCharacter(generated)
+		}
+
+		submitted.ShortURL = shortURL
+		saveCtx, span := startSpan(ctx, "saveURL", attribute.Int("alias.attempt", attempt))
+		var url storage.URL
+		if mode == DedupeAlwaysNew {
+			url, err = s.storage.SaveAdditionalURL(saveCtx, submitted)
+		} else {
+			url, err = s.storage.SaveURL(saveCtx, submitted)
+		}
+		endSpan(span, err)
+		if pooledAlias != "" && err != nil && !errors.Is(err, storage.ErrAliasExists) {
+			// The pooled alias is still free, e.g. the original URL is
+			// already shortened.
+			s.returnPooledAliases(ctx, []string{pooledAlias})
+		}
+		if length != 0 && (err == nil || errors.Is(err, storage.ErrAliasExists)) {
+			s.aliasLength.observe(length, err != nil)
+		}
+		switch {
+		case err == nil:
+			return url, nil
+		case errors.Is(err, storage.ErrOriginalURLExists):
+			if !url.Expired(time.Now()) {
+				if mode == DedupeFailIfExists {
+					return storage.URL{}, fmt.Errorf("%w: already shortened as %q", ErrURLExists, url.ShortURL)
+				}
+				return url, nil
+			}
+			// Purge the expired mapping so that the original URL can be
+			// shortened again.
+			err := s.storage.DeleteURL(ctx, url.ShortURL, !s.allowAliasReuse)
+			if err != nil && !errors.Is(err, storage.ErrURLNotFound) {
+				log.Printf("failed to delete expired url: %v", err)
+				return storage.URL{}, storageError(err)
+			}
+		case errors.Is(err, storage.ErrAliasExists):
+			if customAlias != "" {
+				return storage.URL{}, ErrAliasAlreadyExists
+			}
+			trace.SpanFromContext(ctx).AddEvent("alias collision", trace.WithAttributes(
+				attribute.Int("alias.attempt", attempt)))
+			lastErr = ErrKeyspaceExhausted
+		default:
+			log.Printf("failed to save url: %v", err)
+			if err := storageError(err); err != ErrInternal {
+				return storage.URL{}, err
+			}
+			lastErr = ErrUnavailable
+		}
+	}
+
+	log.Printf("failed to save url after %d attempts: %v", s.maxAttempts, lastErr)
+	return storage.URL{}, lastErr
+}
+
+// CreateShortURLs shortens many URLs with a single multi-row insert. Results
+// are returned in request order; an item that fails does not affect the others.
+func (s *URLShortenerService) CreateShortURLs(ctx context.Context, reqs []CreateRequest) (_ []CreateResult, err error) {
+	ctx, span := startSpan(ctx, "CreateShortURLs")
+	defer func() { endSpan(span, err) }()
+
+	if len(reqs) == 0 {
+		return nil, fmt.Errorf("%w: at least one url is required", ErrInvalidArgument)
+	}
+	if len(reqs) > s.maxBatchSize {
+		return nil, fmt.Errorf("%w: at most %d urls per batch are allowed", ErrInvalidArgument, s.maxBatchSize)
+	}
+
+	results := make([]CreateResult, len(reqs))
+	valid := make([]storage.URL, 0, len(reqs))
+	validIdx := make([]int, 0, len(reqs))
+	customAliases := make(map[string]struct{})
+	now := time.Now()
+	length := s.aliasLength.get()
+
+	var needAlias int
+	for i, req := range reqs {
+		url, err := s.urlPolicy.normalizeURL("original_url", req.OriginalURL)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		url.ExpiresAt = req.ExpiresAt
+		if !req.ExpiresAt.IsZero() && !req.ExpiresAt.After(now) {
+			results[i].Err = fmt.Errorf("%w: expires_at must be in the future", ErrInvalidArgument)
+			continue
+		}
+
+		if shortURL := req.CustomAlias; shortURL != "" {
+			if shortURL, err = s.aliasPolicy.normalizeAlias("custom_alias", shortURL); err != nil {
+				results[i].Err = err
+				continue
+			}
+			if err := s.checkAlias("custom_alias", shortURL); err != nil {
+				results[i].Err = err
+				continue
+			}
+			if _, ok := customAliases[shortURL]; ok {
+				results[i].Err = ErrAliasAlreadyExists
+				continue
+			}
+			customAliases[shortURL] = struct{}{}
+			url.ShortURL = shortURL
+		} else {
+			needAlias++
+		}
+
+		valid = append(valid, url)
+		validIdx = append(validIdx, i)
+	}
+
+	// Pooled aliases are only taken for the items that passed validation.
+	pooled := s.takePooledAliases(ctx, needAlias)
+
+	batch := make([]storage.URL, 0, len(valid))
+	batchIdx := make([]int, 0, len(valid))
+	generated := make([]bool, 0, len(valid)) // whether batch[j] has a freshly generated alias
+	fromPool := make([]bool, 0, len(valid))  // whether batch[j] has a pooled alias
+	for j, url := range valid {
+		i := validIdx[j]
+		isGenerated, isPooled := false, false
+		if url.ShortURL == "" {
+			if len(pooled) > 0 {
+				url.ShortURL, pooled, isPooled = pooled[0], pooled[1:], true
+			} else {
+				alias, err := s.generator.Generate(ctx, url.CanonicalURL, length, 0)
+				if err != nil {
+					log.Printf("failed to generate alias: %v", err)
+					results[i].Err = storageError(err)
+					continue
+				}
+				url.ShortURL, isGenerated = s.appendCheckCharacter(alias), true
+			}
+		}
+
+		batch = append(batch, url)
+		batchIdx = append(batchIdx, i)
+		generated = append(generated, isGenerated)
+		fromPool = append(fromPool, isPooled)
+	}
+
+	if len(batch) == 0 {
+		return results, nil
+	}
+
+	errs, err := s.storage.SaveURLs(ctx, batch)
+
+	// Put back the pooled aliases that were not stored.
+	var unused []string
+	for j, url := range batch {
+		if fromPool[j] && (err != nil || errs[j] != nil && !errors.Is(errs[j], storage.ErrAliasExists)) {
+			unused = append(unused, url.ShortURL)
+		}
+	}
+	s.returnPooledAliases(ctx, unused)
+
+	if err != nil {
+		log.Printf("failed to save urls: %v", err)
+		return nil, storageError(err)
+	}
+
+	for j, err := range errs {
+		i := batchIdx[j]
+		if generated[j] && (err == nil || errors.Is(err, storage.ErrAliasExists)) {
+			s.aliasLength.observe(length, err != nil)
+		}
 		if err == nil {
-			return "", ErrAliasAlreadyExists
+			results[i].URL = batch[j]
+			continue
+		}
+		if !errors.Is(err, storage.ErrOriginalURLExists) && !errors.Is(err, storage.ErrAliasExists) {
+			log.Printf("failed to save url: %v", err)
+			results[i].Err = storageError(err)
+			continue
 		}
+		// Conflicts are rare: resolve them one by one, which applies the
+		// dedupe mode of the item, retries a colliding generated alias or
+		// reports the taken custom alias.
+		results[i].URL, results[i].Err = s.CreateShortURL(ctx, batch[j].OriginalURL, reqs[i].CustomAlias, batch[j].ExpiresAt, reqs[i].DedupeMode)
+	}
+
+	return results, nil
+}
+
+// ImportURLs stores URLs under their existing aliases. The returned slice holds
+// the outcome of every URL: nil if it is stored (including the case when the very
+// same mapping already exists), ErrAliasAlreadyExists, ErrURLExists or an
+// ErrInvalidArgument error.
+func (s *URLShortenerService) ImportURLs(ctx context.Context, urls []storage.URL) (_ []error, err error) {
+	ctx, span := startSpan(ctx, "ImportURLs")
+	defer func() { endSpan(span, err) }()
+
+	results := make([]error, len(urls))
+	batch := make([]storage.URL, 0, len(urls))
+	batchIdx := make([]int, 0, len(urls))
+	aliases := make(map[string]struct{}, len(urls))
 
-		if !errors.Is(err, storage.ErrURLNotFound) {
-			log.Printf("failed to get URL: %v", err)
-			return "", ErrInternal
+	for i, url := range urls {
+		if url.ShortURL == "" {
+			results[i] = fmt.Errorf("%w: short_url is required", ErrInvalidArgument)
+			continue
+		}
+		submitted, err := s.urlPolicy.normalizeURL("original_url", url.OriginalURL)
+		if err != nil {
+			results[i] = err
+			continue
 		}
+		url.OriginalURL, url.CanonicalURL = submitted.OriginalURL, submitted.CanonicalURL
+		if _, ok := aliases[url.ShortURL]; ok {
+			results[i] = ErrAliasAlreadyExists
+			continue
+		}
+		aliases[url.ShortURL] = struct{}{}
+
+		batch = append(batch, url)
+		batchIdx = append(batchIdx, i)
+	}
 
-		shortURL = customAlias
-	} else {
-		shortURL = s.generateUniqueShortURL()
+	if len(batch) == 0 {
+		return results, nil
 	}
 
-	err = s.storage.SaveURL(originalURL, shortURL)
+	errs, err := s.storage.SaveURLs(ctx, batch)
 	if err != nil {
-		if errors.Is(err, storage.ErrURLExists) {
-			shortURL, err := s.getExistingShortURL(ctx, originalURL)
-			if err == nil {
-				return shortURL, nil
-			}
-			log.Printf("failed to get existing short URL: %v", err)
-			return "", ErrInternal
+		log.Printf("failed to save urls: %v", err)
+		return nil, storageError(err)
+	}
+
+	for j, err := range errs {
+		i := batchIdx[j]
+		switch {
+		case err == nil:
+		case errors.Is(err, storage.ErrOriginalURLExists):
+			results[i] = s.importConflict(ctx, batch[j])
+		case errors.Is(err, storage.ErrAliasExists):
+			results[i] = ErrAliasAlreadyExists
+		default:
+			log.Printf("failed to save url: %v", err)
+			results[i] = storageError(err)
 		}
-		log.Printf("failed to save url: %v", err)
-		return "", ErrInternal
 	}
 
-	return shortURL, nil
+	return results, nil
+}
+
+// importConflict resolves an import of url whose canonical form is already
+// shortened. Importing the very same mapping again is not an error.
+func (s *URLShortenerService) importConflict(ctx context.Context, url storage.URL) error {
+	existing, err := s.storage.GetShortURL(ctx, url.CanonicalURL)
+	switch {
+	case err == nil:
+		if existing.ShortURL == url.ShortURL {
+			return nil
+		}
+		return fmt.Errorf("%w: already shortened as %q", ErrURLExists, existing.ShortURL)
+	case errors.Is(err, storage.ErrURLNotFound):
+		// The mapping was deleted concurrently.
+		return ErrAliasAlreadyExists
+	default:
+		log.Printf("failed to get short url: %v", err)
+		return storageError(err)
+	}
 }
 
-func (s *URLShortenerService) GetOriginalURL(ctx context.Context, shortURL string) (string, error) {
+// ExportURLs calls fn for every stored URL ordered by creation time. An error
+// returned by fn stops the export and is returned as is.
+func (s *URLShortenerService) ExportURLs(ctx context.Context, fn func(storage.URL) error) (err error) {
+	ctx, span := startSpan(ctx, "ExportURLs")
+	defer func() { endSpan(span, err) }()
+
+	var fnErr error
+	err = s.storage.ForEachURL(ctx, func(url storage.URL) error {
+		if fnErr = ctx.Err(); fnErr != nil {
+			return fnErr
+		}
+		fnErr = fn(url)
+		return fnErr
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+	if err != nil {
+		log.Printf("failed to export urls: %v", err)
+		return storageError(err)
+	}
+	return nil
+}
+
+// ListURLs returns a page of URLs ordered by creation time and the token of the
+// next page, which is empty on the last page.
+func (s *URLShortenerService) ListURLs(ctx context.Context, req ListRequest) (_ []storage.URL, _ string, err error) {
+	ctx, span := startSpan(ctx, "ListURLs")
+	defer func() { endSpan(span, err) }()
+
+	if req.PageSize < 0 {
+		return nil, "", fmt.Errorf("%w: page_size must not be negative", ErrInvalidArgument)
+	}
+	pageSize := req.PageSize
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+	pageSize = min(pageSize, maxPageSize)
+
+	query := storage.ListQuery{
+		// One extra row tells whether there is a next page.
+		Limit:       pageSize + 1,
+		AliasPrefix: req.AliasPrefix,
+		URLContains: req.URLContains,
+		Descending:  req.Descending,
+	}
+	if req.PageToken != "" {
+		token, err := decodePageToken(req.PageToken)
+		if err != nil || token.Descending != req.Descending {
+			return nil, "", fmt.Errorf("%w: invalid page_token", ErrInvalidArgument)
+		}
+		query.AfterCreatedAt = time.Unix(0, token.CreatedAt)
+		query.AfterShortURL = token.ShortURL
+	}
+
+	urls, err := s.storage.ListURLs(ctx, query)
+	if err != nil {
+		log.Printf("failed to list urls: %v", err)
+		return nil, "", storageError(err)
+	}
+
+	if len(urls) <= pageSize {
+		return urls, "", nil
+	}
+
+	urls = urls[:pageSize]
+	last := urls[len(urls)-1]
+	next := encodePageToken(pageToken{
+		CreatedAt:  last.CreatedAt.UnixNano(),
+		ShortURL:   last.ShortURL,
+		Descending: req.Descending,
+	})
+	return urls, next, nil
+}
+
+func encodePageToken(token pageToken) string {
+	b, _ := json.Marshal(token)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodePageToken(s string) (pageToken, error) {
+	var token pageToken
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return token, err
+	}
+	if err := json.Unmarshal(b, &token); err != nil {
+		return token, err
+	}
+	if token.CreatedAt == 0 {
+		return token, errors.New("empty position")
+	}
+	return token, nil
+}
+
+func (s *URLShortenerService) GetOriginalURL(ctx context.Context, shortURL string) (_ string, err error) {
+	ctx, span := startSpan(ctx, "GetOriginalURL")
+	defer func() { endSpan(span, err) }()
+
 	if shortURL == "" {
-		return "", errors.New("short_url is required")
+		return "", fmt.Errorf("%w: short_url is required", ErrInvalidArgument)
 	}
 
-	originalURL, err := s.storage.GetURL(shortURL)
+	originalURL, err := s.storage.GetURL(ctx, shortURL)
 	if err != nil {
 		if errors.Is(err, storage.ErrURLNotFound) {
+			if s.check != nil && !s.check.Valid(shortURL) {
+				// Aliases stored before check characters were enabled, and
+				// imported ones, are found above; an unknown alias without a
+				// valid check character is most likely mistyped.
+				return "", fmt.Errorf("%w: short_url has an invalid check character", ErrInvalidArgument)
+			}
 			return "", ErrURLNotFound
 		}
+		if errors.Is(err, storage.ErrURLExpired) {
+			return "", ErrURLExpired
+		}
 		log.Printf("failed to get url: %v", err)
-		return "", ErrInternal
+		return "", storageError(err)
+	}
+
+	if s.clicks != nil {
+		info := analytics.ClientInfoFromContext(ctx)
+		s.clicks.Record(storage.Click{
+			ShortURL:      shortURL,
+			ClickedAt:     time.Now(),
+			Referrer:      info.Referrer,
+			UserAgent:     info.UserAgent,
+			ClientAddress: info.ClientAddress,
+		})
 	}
 
 	return originalURL, nil
 }
 
-func (s *URLShortenerService) generateUniqueShortURL() string {
-	const maxAttempts = 10
+// LookupShortURL returns the short URL that CreateShortURL would reuse for
+// originalURL, without creating one. It returns ErrURLNotFound if there is
+// none, including when the existing short URL has expired.
+func (s *URLShortenerService) LookupShortURL(ctx context.Context, originalURL string) (_ storage.URL, err error) {
+	ctx, span := startSpan(ctx, "LookupShortURL")
+	defer func() { endSpan(span, err) }()
 
-	for attempt := 0; attempt < maxAttempts; attempt++ {
-		shortURL := random.NewRandomString(s.shortURLLength)
+	submitted, err := s.urlPolicy.normalizeURL("original_url", originalURL)
+	if err != nil {
+		return storage.URL{}, err
+	}
 
-		_, err := s.storage.GetURL(shortURL)
+	url, err := s.storage.GetShortURL(ctx, submitted.CanonicalURL)
+	if err != nil {
 		if errors.Is(err, storage.ErrURLNotFound) {
-			return shortURL
+			return storage.URL{}, ErrURLNotFound
 		}
+		log.Printf("failed to get short url: %v", err)
+		return storage.URL{}, storageError(err)
+	}
+	if url.Expired(time.Now()) {
+		return storage.URL{}, ErrURLNotFound
+	}
+	return url, nil
+}
+
+// LookupShortURLs is LookupShortURL for many original URLs with a single
+// storage query. Results are returned in request order.
+func (s *URLShortenerService) LookupShortURLs(ctx context.Context, originalURLs []string) (_ []LookupResult, err error) {
+	ctx, span := startSpan(ctx, "LookupShortURLs")
+	defer func() { endSpan(span, err) }()
+
+	if len(originalURLs) == 0 {
+		return nil, fmt.Errorf("%w: at least one url is required", ErrInvalidArgument)
+	}
+	if len(originalURLs) > s.maxBatchSize {
+		return nil, fmt.Errorf("%w: at most %d urls per batch are allowed", ErrInvalidArgument, s.maxBatchSize)
+	}
 
+	results := make([]LookupResult, len(originalURLs))
+	canonicalURLs := make([]string, len(originalURLs))
+	var batch []string
+	for i, originalURL := range originalURLs {
+		submitted, err := s.urlPolicy.normalizeURL("original_url", originalURL)
 		if err != nil {
-			log.Printf("failed to get URL: %v", err)
+			results[i].Err = err
 			continue
 		}
+		canonicalURLs[i] = submitted.CanonicalURL
+		batch = append(batch, submitted.CanonicalURL)
+	}
+	if len(batch) == 0 {
+		return results, nil
+	}
+
+	urls, err := s.storage.GetShortURLs(ctx, batch)
+	if err != nil {
+		log.Printf("failed to get short urls: %v", err)
+		return nil, storageError(err)
+	}
+
+	now := time.Now()
+	for i, canonicalURL := range canonicalURLs {
+		if results[i].Err != nil {
+			continue
+		}
+		url, ok := urls[canonicalURL]
+		if !ok || url.Expired(now) {
+			results[i].Err = ErrURLNotFound
+			continue
+		}
+		results[i].URL = url
+	}
+	return results, nil
+}
+
+// GetURLStats returns the total number of clicks and a per-day histogram
+// covering the last days days, including days without clicks. At most
+// maxStatsDays days are allowed.
+func (s *URLShortenerService) GetURLStats(ctx context.Context, shortURL string, days int) (_ storage.ClickStats, err error) {
+	ctx, span := startSpan(ctx, "GetURLStats")
+	defer func() { endSpan(span, err) }()
+
+	if shortURL == "" {
+		return storage.ClickStats{}, fmt.Errorf("%w: short_url is required", ErrInvalidArgument)
+	}
+	if days <= 0 || days > maxStatsDays {
+		return storage.ClickStats{}, fmt.Errorf("%w: days must be between 1 and %d", ErrInvalidArgument, maxStatsDays)
+	}
+	if s.clicks == nil {
+		return storage.ClickStats{}, ErrAnalyticsDisabled
+	}
+
+	_, err = s.storage.GetURL(ctx, shortURL)
+	if err != nil && !errors.Is(err, storage.ErrURLExpired) {
+		if errors.Is(err, storage.ErrURLNotFound) {
+			return storage.ClickStats{}, ErrURLNotFound
+		}
+		log.Printf("failed to get url: %v", err)
+		return storage.ClickStats{}, storageError(err)
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	since := today.AddDate(0, 0, 1-days)
+
+	stats, err := s.clicks.Stats(ctx, shortURL, since)
+	if err != nil {
+		log.Printf("failed to get click stats: %v", err)
+		return storage.ClickStats{}, storageError(err)
+	}
 
+	counts := make(map[time.Time]int64, len(stats.Daily))
+	for _, d := range stats.Daily {
+		counts[d.Day] = d.Clicks
+	}
+	daily := make([]storage.DailyClicks, 0, days)
+	for day := since; !day.After(today); day = day.AddDate(0, 0, 1) {
+		daily = append(daily, storage.DailyClicks{Day: day, Clicks: counts[day]})
 	}
+	stats.Daily = daily
 
-	panic("failed to generate unique short URL")
+	return stats, nil
 }
 
-func (s *URLShortenerService) getExistingShortURL(ctx context.Context, originalURL string) (string, error) {
-	shortURL, err := s.storage.GetShortURL(originalURL)
+func (s *URLShortenerService) DeleteShortURL(ctx context.Context, shortURL string) (err error) {
+	ctx, span := startSpan(ctx, "DeleteShortURL")
+	defer func() { endSpan(span, err) }()
+
+	if shortURL == "" {
+		return fmt.Errorf("%w: short_url is required", ErrInvalidArgument)
+	}
+
+	err = s.storage.DeleteURL(ctx, shortURL, !s.allowAliasReuse)
 	if err != nil {
 		if errors.Is(err, storage.ErrURLNotFound) {
-			return "", ErrURLNotFound
+			return ErrURLNotFound
+		}
+		log.Printf("failed to delete url: %v", err)
+		return storageError(err)
+	}
+
+	return nil
+}
+
+// UpdateShortURL points shortURL at originalURL and returns the URL as stored.
+func (s *URLShortenerService) UpdateShortURL(ctx context.Context, shortURL string, originalURL string) (_ storage.URL, err error) {
+	ctx, span := startSpan(ctx, "UpdateShortURL")
+	defer func() { endSpan(span, err) }()
+
+	if shortURL == "" {
+		return storage.URL{}, fmt.Errorf("%w: short_url is required", ErrInvalidArgument)
+	}
+	newURL, err := s.urlPolicy.normalizeURL("original_url", originalURL)
+	if err != nil {
+		return storage.URL{}, err
+	}
+
+	err = s.storage.UpdateURL(ctx, shortURL, newURL)
+	if err != nil {
+		if errors.Is(err, storage.ErrURLNotFound) {
+			return storage.URL{}, ErrURLNotFound
 		}
-		log.Printf("failed to get short URL: %v", err)
-		return "", ErrInternal
+		if errors.Is(err, storage.ErrOriginalURLExists) {
+			return storage.URL{}, ErrURLExists
+		}
+		log.Printf("failed to update url: %v", err)
+		return storage.URL{}, storageError(err)
 	}
 
-	return shortURL, nil
+	newURL.ShortURL = shortURL
+	return newURL, nil
+}
+
+func (s *URLShortenerService) appendCheckCharacter(alias string) string {
+	if s.check == nil {
+		return alias
+	}
+	c, _ := s.check.CheckCharacter(alias)
+	return alias + string(c)
+}
+
+// checkAlias verifies the check character of a client supplied alias.
+func (s *URLShortenerService) checkAlias(field string, alias string) error {
+	if s.check == nil || s.check.Valid(alias) {
+		return nil
+	}
+	c, ok := s.check.CheckCharacter(alias)
+	if !ok {
+		return fmt.Errorf("%w: %s must consist of the characters %q", ErrInvalidArgument, field, s.check.String())
+	}
+	return fmt.Errorf("%w: %s must end with a check character, e.g. %q", ErrInvalidArgument, field, alias+string(c))
+}
+
+// storageError converts an unexpected storage error into a service error,
+// keeping deadlines and cancellations distinguishable from internal failures.
+func storageError(err error) error {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrDeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		return ErrCanceled
+	default:
+		return ErrInternal
+	}
+}
+
+// ExpirationTime resolves the expiration of a new short URL from either a TTL
+// or an absolute time. The zero time means the short URL never expires.
+func ExpirationTime(ttl time.Duration, expiresAt time.Time) (time.Time, error) {
+	if ttl != 0 && !expiresAt.IsZero() {
+		return time.Time{}, fmt.Errorf("%w: only one of ttl and expires_at may be set", ErrInvalidArgument)
+	}
+	if ttl < 0 {
+		return time.Time{}, fmt.Errorf("%w: ttl must be positive", ErrInvalidArgument)
+	}
+	if ttl > 0 {
+		return time.Now().Add(ttl), nil
+	}
+	return expiresAt, nil
+}
+
+// RunExpirationSweeper purges expired short URLs every interval until ctx is done.
+func (s *URLShortenerService) RunExpirationSweeper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.PurgeExpiredURLs(ctx); err != nil {
+				log.Printf("failed to purge expired urls: %v", err)
+			}
+		}
+	}
+}
+
+// BackfillCanonicalURLs computes the canonical form of URLs stored before
+// canonical forms were introduced. It does nothing if the storage keeps no
+// such URLs.
+func (s *URLShortenerService) BackfillCanonicalURLs(ctx context.Context) (int, error) {
+	backfiller, ok := s.storage.(storage.CanonicalBackfiller)
+	if !ok {
+		return 0, nil
+	}
+
+	updated, err := backfiller.BackfillCanonicalURLs(ctx, func(originalURL string) (string, error) {
+		return s.urlPolicy.canonicalURL("original_url", strings.TrimSpace(originalURL))
+	})
+	if err != nil {
+		return updated, fmt.Errorf("failed to backfill canonical urls: %w", err)
+	}
+	if updated > 0 {
+		log.Printf("backfilled canonical form of %d urls", updated)
+	}
+	return updated, nil
+}
+
+func (s *URLShortenerService) PurgeExpiredURLs(ctx context.Context) (_ int64, err error) {
+	ctx, span := startSpan(ctx, "PurgeExpiredURLs")
+	defer func() { endSpan(span, err) }()
+
+	deleted, err := s.storage.DeleteExpired(ctx, time.Now(), !s.allowAliasReuse)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired urls: %w", err)
+	}
+	if deleted > 0 {
+		log.Printf("purged %d expired urls", deleted)
+	}
+	return deleted, nil
 }
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"url-shortener/internal/storage"
+)
+
+const tracerName = "url-shortener/internal/service"
+
+// startSpan starts the span of the service step name. The tracer is looked up
+// on every call, so that it follows the global tracer provider.
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, "URLShortenerService."+name, trace.WithAttributes(attrs...))
+}
+
+// endSpan records err on span and ends it. Only failures of the service mark
+// the span as failed; rejected requests, such as an invalid argument or an
+// unknown alias, are recorded as events.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		if !expectedError(err) {
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+	span.End()
+}
+
+// expectedError tells whether err is an outcome of the request rather than a
+// failure of the service or the storage.
+func expectedError(err error) bool {
+	for _, target := range []error{
+		ErrInvalidArgument,
+		ErrURLNotFound,
+		ErrURLExpired,
+		ErrURLExists,
+		ErrAliasAlreadyExists,
+		ErrAnalyticsDisabled,
+		storage.ErrURLNotFound,
+		storage.ErrURLExpired,
+		storage.ErrAliasExists,
+		storage.ErrOriginalURLExists,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,60 +1,400 @@
 package memory
 
 import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"url-shortener/internal/storage"
 )
 
+type entry struct {
+	originalURL  string
+	canonicalURL string
+	createdAt    time.Time
+	expiresAt    time.Time
+}
+
 type MemoryStorage struct {
 	mu      sync.RWMutex
-	data    map[string]string
-	revData map[string]string
+	data    map[string]entry
+	revData map[string][]string // canonical URL to its aliases, oldest first
+	retired map[string]struct{}
+	clicks  map[string][]storage.Click
+	// order holds the aliases of data sorted by (createdAt, alias).
+	order       []string
+	lastCreated time.Time
+	lastAliasID int64
+	pool        []string
+	pooled      map[string]struct{}
 }
 
 func New() *MemoryStorage {
 	return &MemoryStorage{
-		data:    make(map[string]string),
-		revData: make(map[string]string),
+		data:    make(map[string]entry),
+		revData: make(map[string][]string),
+		retired: make(map[string]struct{}),
+		clicks:  make(map[string][]storage.Click),
+		pooled:  make(map[string]struct{}),
+	}
+}
+
+func (s *MemoryStorage) SaveURL(ctx context.Context, url storage.URL) (storage.URL, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.save(url, true); err != nil {
+		if errors.Is(err, storage.ErrOriginalURLExists) {
+			existing := s.revData[url.Canonical()][0]
+			return s.data[existing].toURL(existing), err
+		}
+		return storage.URL{}, err
 	}
+	return s.data[url.ShortURL].toURL(url.ShortURL), nil
 }
 
-func (s *MemoryStorage) SaveURL(originalURL string, shortURL string) error {
+func (s *MemoryStorage) SaveAdditionalURL(ctx context.Context, url storage.URL) (storage.URL, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if err := s.save(url, false); err != nil {
+		return storage.URL{}, err
+	}
+	return s.data[url.ShortURL].toURL(url.ShortURL), nil
+}
+
+func (s *MemoryStorage) SaveURLs(ctx context.Context, urls []storage.URL) ([]error, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	errs := make([]error, len(urls))
+	for i, url := range urls {
+		errs[i] = s.save(url, true)
+	}
+	return errs, nil
+}
+
+// save inserts url. With dedupe set, a URL whose canonical form is shortened
+// already is rejected.
+func (s *MemoryStorage) save(url storage.URL, dedupe bool) error {
+	shortURL, canonicalURL := url.ShortURL, url.Canonical()
+	if _, ok := s.revData[canonicalURL]; ok && dedupe {
+		return storage.ErrOriginalURLExists
+	}
 	if _, ok := s.data[shortURL]; ok {
-		return storage.ErrURLExists
+		return storage.ErrAliasExists
+	}
+	if _, ok := s.retired[shortURL]; ok {
+		return storage.ErrAliasExists
+	}
+
+	// Creation times never go backwards, so new aliases almost always end up at
+	// the tail of order. Imported aliases keep their own creation time.
+	createdAt := url.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+		if createdAt.Before(s.lastCreated) {
+			createdAt = s.lastCreated
+		}
+		s.lastCreated = createdAt
 	}
-	if _, ok := s.revData[originalURL]; ok {
-		return storage.ErrURLExists
+
+	s.data[shortURL] = entry{
+		originalURL:  url.OriginalURL,
+		canonicalURL: canonicalURL,
+		createdAt:    createdAt,
+		expiresAt:    url.ExpiresAt,
 	}
+	s.revData[canonicalURL] = append(s.revData[canonicalURL], shortURL)
 
-	s.data[shortURL] = originalURL
-	s.revData[originalURL] = shortURL
+	i := s.position(createdAt, shortURL)
+	s.order = append(s.order, "")
+	copy(s.order[i+1:], s.order[i:])
+	s.order[i] = shortURL
 	return nil
 }
 
-func (s *MemoryStorage) GetURL(shortURL string) (string, error) {
+// position returns the index of the first alias in order that is not less than
+// (createdAt, shortURL).
+func (s *MemoryStorage) position(createdAt time.Time, shortURL string) int {
+	return sort.Search(len(s.order), func(i int) bool {
+		e := s.data[s.order[i]]
+		if !e.createdAt.Equal(createdAt) {
+			return e.createdAt.After(createdAt)
+		}
+		return s.order[i] >= shortURL
+	})
+}
+
+func (s *MemoryStorage) NextAliasID(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastAliasID++
+	return s.lastAliasID, nil
+}
+
+func (s *MemoryStorage) AddPoolAliases(ctx context.Context, aliases []string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var added int
+	for _, alias := range aliases {
+		if _, ok := s.pooled[alias]; ok {
+			continue
+		}
+		if _, ok := s.data[alias]; ok {
+			continue
+		}
+		if _, ok := s.retired[alias]; ok {
+			continue
+		}
+		s.pooled[alias] = struct{}{}
+		s.pool = append(s.pool, alias)
+		added++
+	}
+	return added, nil
+}
+
+func (s *MemoryStorage) TakePoolAliases(ctx context.Context, n int) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n = min(n, len(s.pool))
+	aliases := make([]string, n)
+	copy(aliases, s.pool[len(s.pool)-n:])
+	s.pool = s.pool[:len(s.pool)-n]
+	for _, alias := range aliases {
+		delete(s.pooled, alias)
+	}
+	return aliases, nil
+}
+
+func (s *MemoryStorage) PoolSize(ctx context.Context) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.pool), nil
+}
+
+func (s *MemoryStorage) GetURL(ctx context.Context, shortURL string) (string, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	originalURL, ok := s.data[shortURL]
+	e, ok := s.data[shortURL]
 	if !ok {
 		return "", storage.ErrURLNotFound
 	}
+	if e.toURL(shortURL).Expired(time.Now()) {
+		return "", storage.ErrURLExpired
+	}
 
-	return originalURL, nil
+	return e.originalURL, nil
 }
 
-func (s *MemoryStorage) GetShortURL(originalURL string) (string, error) {
+func (s *MemoryStorage) GetShortURL(ctx context.Context, canonicalURL string) (storage.URL, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	shortURL, ok := s.revData[originalURL]
+	aliases, ok := s.revData[canonicalURL]
 	if !ok {
-		return "", storage.ErrURLNotFound
+		return storage.URL{}, storage.ErrURLNotFound
 	}
 
-	return shortURL, nil
+	return s.data[aliases[0]].toURL(aliases[0]), nil
+}
+
+func (s *MemoryStorage) GetShortURLs(ctx context.Context, canonicalURLs []string) (map[string]storage.URL, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	urls := make(map[string]storage.URL, len(canonicalURLs))
+	for _, canonicalURL := range canonicalURLs {
+		if aliases, ok := s.revData[canonicalURL]; ok {
+			urls[canonicalURL] = s.data[aliases[0]].toURL(aliases[0])
+		}
+	}
+	return urls, nil
+}
+
+func (s *MemoryStorage) DeleteURL(ctx context.Context, shortURL string, retire bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.data[shortURL]; !ok {
+		return storage.ErrURLNotFound
+	}
+
+	s.delete(shortURL, retire)
+	return nil
+}
+
+func (s *MemoryStorage) UpdateURL(ctx context.Context, shortURL string, newURL storage.URL) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.data[shortURL]
+	if !ok {
+		return storage.ErrURLNotFound
+	}
+	canonicalURL := newURL.Canonical()
+	if canonicalURL != e.canonicalURL {
+		if _, ok := s.revData[canonicalURL]; ok {
+			return storage.ErrOriginalURLExists
+		}
+		s.removeAlias(e.canonicalURL, shortURL)
+		s.revData[canonicalURL] = []string{shortURL}
+	}
+	e.originalURL = newURL.OriginalURL
+	e.canonicalURL = canonicalURL
+	s.data[shortURL] = e
+	return nil
+}
+
+func (s *MemoryStorage) DeleteExpired(ctx context.Context, now time.Time, retire bool) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var deleted int64
+	for shortURL, e := range s.data {
+		if e.toURL(shortURL).Expired(now) {
+			s.delete(shortURL, retire)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+func (s *MemoryStorage) ForEachURL(ctx context.Context, fn func(storage.URL) error) error {
+	s.mu.RLock()
+	urls := make([]storage.URL, 0, len(s.order))
+	for _, shortURL := range s.order {
+		urls = append(urls, s.data[shortURL].toURL(shortURL))
+	}
+	s.mu.RUnlock()
+
+	for _, url := range urls {
+		if err := fn(url); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStorage) ListURLs(ctx context.Context, query storage.ListQuery) ([]storage.URL, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	matches := func(shortURL string) bool {
+		return strings.HasPrefix(shortURL, query.AliasPrefix) &&
+			strings.Contains(s.data[shortURL].originalURL, query.URLContains)
+	}
+
+	urls := make([]storage.URL, 0, query.Limit)
+	if !query.Descending {
+		start := 0
+		if !query.AfterCreatedAt.IsZero() {
+			start = s.position(query.AfterCreatedAt, query.AfterShortURL)
+			if start < len(s.order) && s.order[start] == query.AfterShortURL {
+				start++
+			}
+		}
+		for i := start; i < len(s.order) && len(urls) < query.Limit; i++ {
+			if matches(s.order[i]) {
+				urls = append(urls, s.data[s.order[i]].toURL(s.order[i]))
+			}
+		}
+		return urls, nil
+	}
+
+	start := len(s.order) - 1
+	if !query.AfterCreatedAt.IsZero() {
+		start = s.position(query.AfterCreatedAt, query.AfterShortURL) - 1
+	}
+	for i := start; i >= 0 && len(urls) < query.Limit; i-- {
+		if matches(s.order[i]) {
+			urls = append(urls, s.data[s.order[i]].toURL(s.order[i]))
+		}
+	}
+	return urls, nil
+}
+
+func (s *MemoryStorage) SaveClicks(ctx context.Context, clicks []storage.Click) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, c := range clicks {
+		// Clicks buffered before their alias was deleted are dropped, even if
+		// the alias was created again since.
+		if e, ok := s.data[c.ShortURL]; !ok || c.ClickedAt.Before(e.createdAt) {
+			continue
+		}
+		s.clicks[c.ShortURL] = append(s.clicks[c.ShortURL], c)
+	}
+	return nil
+}
+
+func (s *MemoryStorage) GetClickStats(ctx context.Context, shortURL string, since time.Time) (storage.ClickStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	clicks := s.clicks[shortURL]
+	stats := storage.ClickStats{Total: int64(len(clicks))}
+
+	perDay := make(map[time.Time]int64)
+	for _, c := range clicks {
+		if c.ClickedAt.Before(since) {
+			continue
+		}
+		perDay[c.ClickedAt.UTC().Truncate(24*time.Hour)]++
+	}
+	for day, n := range perDay {
+		stats.Daily = append(stats.Daily, storage.DailyClicks{Day: day, Clicks: n})
+	}
+	sort.Slice(stats.Daily, func(i, j int) bool {
+		return stats.Daily[i].Day.Before(stats.Daily[j].Day)
+	})
+
+	return stats, nil
+}
+
+func (s *MemoryStorage) delete(shortURL string, retire bool) {
+	e := s.data[shortURL]
+	if i := s.position(e.createdAt, shortURL); i < len(s.order) && s.order[i] == shortURL {
+		s.order = append(s.order[:i], s.order[i+1:]...)
+	}
+
+	s.removeAlias(e.canonicalURL, shortURL)
+	delete(s.data, shortURL)
+	delete(s.clicks, shortURL)
+	if retire {
+		s.retired[shortURL] = struct{}{}
+	}
+}
+
+// removeAlias removes shortURL from the aliases of canonicalURL.
+func (s *MemoryStorage) removeAlias(canonicalURL string, shortURL string) {
+	aliases := slices.DeleteFunc(s.revData[canonicalURL], func(alias string) bool {
+		return alias == shortURL
+	})
+	if len(aliases) == 0 {
+		delete(s.revData, canonicalURL)
+		return
+	}
+	s.revData[canonicalURL] = aliases
+}
+
+func (e entry) toURL(shortURL string) storage.URL {
+	return storage.URL{
+		ShortURL:     shortURL,
+		OriginalURL:  e.originalURL,
+		CanonicalURL: e.canonicalURL,
+		CreatedAt:    e.createdAt,
+		ExpiresAt:    e.expiresAt,
+	}
 }
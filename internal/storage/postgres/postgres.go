@@ -5,19 +5,43 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"sync"
+	"strings"
+	"time"
 
 	"github.com/lib/pq"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 
 	"url-shortener/internal/storage"
 )
 
+const tracerName = "url-shortener/internal/storage/postgres"
+
 type PostgresStorage struct {
-	Db *sql.DB
-	mu sync.Mutex
+	Db       *sql.DB
+	timeouts Timeouts
+}
+
+// Timeouts bound every query on top of the deadline of the caller's context.
+// A zero timeout means the query is bounded by the context only.
+type Timeouts struct {
+	Read  time.Duration
+	Write time.Duration
+	// Batch applies to statements touching many rows at once.
+	Batch time.Duration
+}
+
+type Option func(*PostgresStorage)
+
+func WithTimeouts(timeouts Timeouts) Option {
+	return func(s *PostgresStorage) {
+		s.timeouts = timeouts
+	}
 }
 
-func New(dataSourceName string) (*PostgresStorage, error) {
+func New(dataSourceName string, opts ...Option) (*PostgresStorage, error) {
 	db, err := sql.Open("postgres", dataSourceName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
@@ -33,67 +57,815 @@ func New(dataSourceName string) (*PostgresStorage, error) {
 			short_url TEXT PRIMARY KEY,
 			original_url TEXT NOT NULL UNIQUE
 		);
+
+		ALTER TABLE urls ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ;
+		ALTER TABLE urls ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT now();
+		CREATE INDEX IF NOT EXISTS urls_created_at_short_url_idx ON urls (created_at, short_url);
+		CREATE INDEX IF NOT EXISTS urls_short_url_pattern_idx ON urls (short_url text_pattern_ops);
+		CREATE INDEX IF NOT EXISTS urls_expires_at_idx ON urls (expires_at) WHERE expires_at IS NOT NULL;
+		-- Rows created before canonical forms have none until
+		-- BackfillCanonicalURLs runs.
+		ALTER TABLE urls ADD COLUMN IF NOT EXISTS canonical_url TEXT;
+		-- An original URL may have several aliases. Only rows inserted by
+		-- the deduplicating SaveURL are primary, at most one per canonical form.
+		ALTER TABLE urls DROP CONSTRAINT IF EXISTS urls_original_url_key;
+		ALTER TABLE urls ADD COLUMN IF NOT EXISTS is_primary BOOLEAN NOT NULL DEFAULT true;
+		DROP INDEX IF EXISTS urls_canonical_url_idx;
+		CREATE UNIQUE INDEX IF NOT EXISTS urls_canonical_url_primary_idx ON urls (canonical_url) WHERE is_primary;
+		CREATE INDEX IF NOT EXISTS urls_canonical_url_created_at_idx ON urls (canonical_url, created_at, short_url);
+
+		CREATE SEQUENCE IF NOT EXISTS alias_id_seq;
+
+		CREATE TABLE IF NOT EXISTS alias_pool (
+			short_url TEXT PRIMARY KEY
+		);
+
+		CREATE TABLE IF NOT EXISTS deleted_aliases (
+			short_url TEXT PRIMARY KEY,
+			deleted_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		);
+
+		CREATE TABLE IF NOT EXISTS clicks (
+			id BIGSERIAL PRIMARY KEY,
+			short_url TEXT NOT NULL,
+			clicked_at TIMESTAMPTZ NOT NULL,
+			referrer TEXT NOT NULL DEFAULT '',
+			user_agent TEXT NOT NULL DEFAULT '',
+			client_address TEXT NOT NULL DEFAULT ''
+		);
+		CREATE INDEX IF NOT EXISTS clicks_short_url_clicked_at_idx ON clicks (short_url, clicked_at);
 	`)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create table: %w", err)
 	}
 
-	return &PostgresStorage{Db: db}, nil
+	s := &PostgresStorage{Db: db}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
 }
 
-func (s *PostgresStorage) SaveURL(urlToSave string, alias string) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+func (s *PostgresStorage) SaveURL(ctx context.Context, url storage.URL) (storage.URL, error) {
+	ctx, span := startSpan(ctx, "SaveURL")
+	defer span.End()
+
+	ctx, cancel := withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+
+	// The insert and the lookup of an existing mapping run as one statement.
+	// The lookup only sees rows committed before the statement started, so a
+	// mapping inserted concurrently is looked up again below.
+	saved := storage.URL{CanonicalURL: url.Canonical()}
+	var createdAt, expiresAt sql.NullTime
+	var inserted bool
+	err := s.Db.QueryRowContext(ctx, `
+		WITH inserted AS (
+			INSERT INTO urls (short_url, original_url, canonical_url, expires_at)
+			SELECT $1, $2, $3, $4
+			WHERE NOT EXISTS (SELECT 1 FROM deleted_aliases WHERE short_url = $1)
+			AND NOT EXISTS (SELECT 1 FROM urls WHERE canonical_url = $3)
+			ON CONFLICT DO NOTHING
+			RETURNING short_url, original_url, created_at, expires_at
+		)
+		SELECT short_url, original_url, created_at, expires_at, true FROM inserted
+		UNION ALL
+		(SELECT short_url, original_url, created_at, expires_at, false FROM urls
+		WHERE canonical_url = $3 AND NOT EXISTS (SELECT 1 FROM inserted)
+		ORDER BY created_at, short_url LIMIT 1)`,
+		url.ShortURL, url.OriginalURL, saved.CanonicalURL, nullTime(url.ExpiresAt),
+	).Scan(&saved.ShortURL, &saved.OriginalURL, &createdAt, &expiresAt, &inserted)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		existing, err := s.GetShortURL(ctx, saved.CanonicalURL)
+		if errors.Is(err, storage.ErrURLNotFound) {
+			return storage.URL{}, storage.ErrAliasExists
+		}
+		if err != nil {
+			return storage.URL{}, err
+		}
+		return existing, storage.ErrOriginalURLExists
+	case err != nil:
+		return storage.URL{}, queryError(ctx, "failed to insert url", err)
+	}
+	saved.CreatedAt = createdAt.Time
+	saved.ExpiresAt = expiresAt.Time
 
-	_, err := s.Db.ExecContext(context.Background(),
-		"INSERT INTO urls (short_url, original_url) VALUES ($1, $2)",
-		alias, urlToSave,
+	if !inserted {
+		return saved, storage.ErrOriginalURLExists
+	}
+	return saved, nil
+}
+
+// SaveAdditionalURL inserts the mapping regardless of other aliases of the
+// original URL. The row is never primary, so it cannot conflict with a
+// concurrent SaveURL of the same original URL.
+func (s *PostgresStorage) SaveAdditionalURL(ctx context.Context, url storage.URL) (storage.URL, error) {
+	ctx, span := startSpan(ctx, "SaveAdditionalURL")
+	defer span.End()
+
+	ctx, cancel := withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+
+	saved := storage.URL{CanonicalURL: url.Canonical()}
+	var expiresAt sql.NullTime
+	err := s.Db.QueryRowContext(ctx, `
+		INSERT INTO urls (short_url, original_url, canonical_url, expires_at, is_primary)
+		SELECT $1, $2, $3, $4, false
+		WHERE NOT EXISTS (SELECT 1 FROM deleted_aliases WHERE short_url = $1)
+		ON CONFLICT DO NOTHING
+		RETURNING short_url, original_url, created_at, expires_at`,
+		url.ShortURL, url.OriginalURL, saved.CanonicalURL, nullTime(url.ExpiresAt),
+	).Scan(&saved.ShortURL, &saved.OriginalURL, &saved.CreatedAt, &expiresAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return storage.URL{}, storage.ErrAliasExists
+		}
+		return storage.URL{}, queryError(ctx, "failed to insert url", err)
+	}
+	saved.ExpiresAt = expiresAt.Time
+
+	return saved, nil
+}
+
+// SaveURLs inserts all URLs with a single multi-row statement. Conflicting rows
+// are skipped instead of aborting the statement.
+func (s *PostgresStorage) SaveURLs(ctx context.Context, urls []storage.URL) ([]error, error) {
+	ctx, span := startSpan(ctx, "SaveURLs")
+	defer span.End()
+
+	ctx, cancel := withTimeout(ctx, s.timeouts.Batch)
+	defer cancel()
+
+	aliases := make([]string, len(urls))
+	originalURLs := make([]string, len(urls))
+	canonicalURLs := make([]string, len(urls))
+	expiresAt := make([]string, len(urls))
+	createdAt := make([]string, len(urls))
+	for i, url := range urls {
+		aliases[i] = url.ShortURL
+		originalURLs[i] = url.OriginalURL
+		canonicalURLs[i] = url.Canonical()
+		if !url.ExpiresAt.IsZero() {
+			expiresAt[i] = url.ExpiresAt.Format(time.RFC3339Nano)
+		}
+		if !url.CreatedAt.IsZero() {
+			createdAt[i] = url.CreatedAt.Format(time.RFC3339Nano)
+		}
+	}
+
+	rows, err := s.Db.QueryContext(ctx, `
+		INSERT INTO urls (short_url, original_url, canonical_url, expires_at, created_at)
+		SELECT t.short_url, t.original_url, t.canonical_url, NULLIF(t.expires_at, '')::timestamptz,
+			COALESCE(NULLIF(t.created_at, '')::timestamptz, now())
+		FROM unnest($1::text[], $2::text[], $3::text[], $4::text[], $5::text[])
+			AS t(short_url, original_url, canonical_url, expires_at, created_at)
+		WHERE NOT EXISTS (SELECT 1 FROM deleted_aliases d WHERE d.short_url = t.short_url)
+		AND NOT EXISTS (SELECT 1 FROM urls u WHERE u.canonical_url = t.canonical_url)
+		ON CONFLICT DO NOTHING
+		RETURNING short_url, original_url`,
+		pq.Array(aliases), pq.Array(originalURLs), pq.Array(canonicalURLs), pq.Array(expiresAt), pq.Array(createdAt),
 	)
 	if err != nil {
-		var pqErr *pq.Error
-		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
-			return storage.ErrURLExists
+		return nil, queryError(ctx, "failed to insert urls", err)
+	}
+	defer rows.Close()
+
+	inserted := make(map[storage.URL]struct{}, len(urls))
+	for rows.Next() {
+		var url storage.URL
+		if err := rows.Scan(&url.ShortURL, &url.OriginalURL); err != nil {
+			return nil, queryError(ctx, "failed to scan inserted url", err)
 		}
-		return fmt.Errorf("failed to insert url: %w", err)
+		inserted[url] = struct{}{}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, queryError(ctx, "failed to insert urls", err)
 	}
 
-	return nil
+	errs := make([]error, len(urls))
+	var conflicts []string
+	for i, url := range urls {
+		key := storage.URL{ShortURL: url.ShortURL, OriginalURL: url.OriginalURL}
+		if _, ok := inserted[key]; ok {
+			// A mapping repeated in the same batch is inserted once.
+			delete(inserted, key)
+			continue
+		}
+		conflicts = append(conflicts, url.Canonical())
+		errs[i] = storage.ErrAliasExists
+	}
+	if len(conflicts) == 0 {
+		return errs, nil
+	}
+
+	// Tell original URL conflicts from alias conflicts.
+	rows, err = s.Db.QueryContext(ctx,
+		"SELECT canonical_url FROM urls WHERE canonical_url = ANY($1)", pq.Array(conflicts))
+	if err != nil {
+		return nil, queryError(ctx, "failed to get conflicting urls", err)
+	}
+	defer rows.Close()
+
+	shortened := make(map[string]struct{}, len(conflicts))
+	for rows.Next() {
+		var canonicalURL string
+		if err := rows.Scan(&canonicalURL); err != nil {
+			return nil, queryError(ctx, "failed to scan conflicting url", err)
+		}
+		shortened[canonicalURL] = struct{}{}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, queryError(ctx, "failed to get conflicting urls", err)
+	}
+
+	for i, url := range urls {
+		if errs[i] == nil {
+			continue
+		}
+		if _, ok := shortened[url.Canonical()]; ok {
+			errs[i] = storage.ErrOriginalURLExists
+		}
+	}
+	return errs, nil
 }
 
-func (s *PostgresStorage) GetURL(alias string) (string, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// NextAliasID returns the next value of the sequence behind counter aliases.
+func (s *PostgresStorage) NextAliasID(ctx context.Context) (int64, error) {
+	ctx, span := startSpan(ctx, "NextAliasID")
+	defer span.End()
+
+	ctx, cancel := withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
 
-	var originalURL string
-	err := s.Db.QueryRowContext(context.Background(),
-		"SELECT original_url FROM urls WHERE short_url = $1", alias).Scan(&originalURL)
+	var id int64
+	err := s.Db.QueryRowContext(ctx, "SELECT nextval('alias_id_seq')").Scan(&id)
+	if err != nil {
+		return 0, queryError(ctx, "failed to get next alias id", err)
+	}
+	return id, nil
+}
+
+func (s *PostgresStorage) AddPoolAliases(ctx context.Context, aliases []string) (int, error) {
+	ctx, span := startSpan(ctx, "AddPoolAliases")
+	defer span.End()
+
+	ctx, cancel := withTimeout(ctx, s.timeouts.Batch)
+	defer cancel()
+
+	res, err := s.Db.ExecContext(ctx, `
+		INSERT INTO alias_pool (short_url)
+		SELECT a FROM unnest($1::text[]) AS a
+		WHERE NOT EXISTS (SELECT 1 FROM urls WHERE short_url = a)
+			AND NOT EXISTS (SELECT 1 FROM deleted_aliases WHERE short_url = a)
+		ON CONFLICT DO NOTHING`,
+		pq.Array(aliases),
+	)
+	if err != nil {
+		return 0, queryError(ctx, "failed to add pool aliases", err)
+	}
+
+	added, err := res.RowsAffected()
+	if err != nil {
+		return 0, queryError(ctx, "failed to add pool aliases", err)
+	}
+	return int(added), nil
+}
+
+// TakePoolAliases skips rows locked by concurrent callers instead of waiting
+// for them.
+func (s *PostgresStorage) TakePoolAliases(ctx context.Context, n int) ([]string, error) {
+	ctx, span := startSpan(ctx, "TakePoolAliases")
+	defer span.End()
+
+	ctx, cancel := withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+
+	rows, err := s.Db.QueryContext(ctx, `
+		DELETE FROM alias_pool
+		WHERE short_url IN (
+			SELECT short_url FROM alias_pool LIMIT $1 FOR UPDATE SKIP LOCKED
+		)
+		RETURNING short_url`,
+		n,
+	)
+	if err != nil {
+		return nil, queryError(ctx, "failed to take pool aliases", err)
+	}
+	defer rows.Close()
+
+	aliases := make([]string, 0, n)
+	for rows.Next() {
+		var alias string
+		if err := rows.Scan(&alias); err != nil {
+			return nil, queryError(ctx, "failed to scan pool alias", err)
+		}
+		aliases = append(aliases, alias)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, queryError(ctx, "failed to take pool aliases", err)
+	}
+	return aliases, nil
+}
+
+func (s *PostgresStorage) PoolSize(ctx context.Context) (int, error) {
+	ctx, span := startSpan(ctx, "PoolSize")
+	defer span.End()
+
+	ctx, cancel := withTimeout(ctx, s.timeouts.Read)
+	defer cancel()
+
+	var size int
+	err := s.Db.QueryRowContext(ctx, "SELECT count(*) FROM alias_pool").Scan(&size)
+	if err != nil {
+		return 0, queryError(ctx, "failed to count pool aliases", err)
+	}
+	return size, nil
+}
+
+func (s *PostgresStorage) GetURL(ctx context.Context, alias string) (string, error) {
+	ctx, span := startSpan(ctx, "GetURL")
+	defer span.End()
+
+	ctx, cancel := withTimeout(ctx, s.timeouts.Read)
+	defer cancel()
+
+	var url storage.URL
+	var expiresAt sql.NullTime
+	err := s.Db.QueryRowContext(ctx,
+		"SELECT original_url, expires_at FROM urls WHERE short_url = $1", alias).Scan(&url.OriginalURL, &expiresAt)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return "", storage.ErrURLNotFound
 		}
-		return "", fmt.Errorf("failed to get url: %w", err)
+		return "", queryError(ctx, "failed to get url", err)
+	}
+	url.ExpiresAt = expiresAt.Time
+
+	if url.Expired(time.Now()) {
+		return "", storage.ErrURLExpired
 	}
 
-	return originalURL, nil
+	return url.OriginalURL, nil
 }
 
-func (s *PostgresStorage) GetShortURL(originalURL string) (string, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// GetShortURL returns the mapping of the original URL with the given
+// canonical form.
+func (s *PostgresStorage) GetShortURL(ctx context.Context, canonicalURL string) (storage.URL, error) {
+	ctx, span := startSpan(ctx, "GetShortURL")
+	defer span.End()
+
+	ctx, cancel := withTimeout(ctx, s.timeouts.Read)
+	defer cancel()
 
-	var shortURL string
-	err := s.Db.QueryRowContext(context.Background(),
-		"SELECT short_url FROM urls WHERE original_url = $1", originalURL).Scan(&shortURL)
+	url := storage.URL{CanonicalURL: canonicalURL}
+	var expiresAt sql.NullTime
+	err := s.Db.QueryRowContext(ctx,
+		`SELECT short_url, original_url, created_at, expires_at FROM urls
+		WHERE canonical_url = $1 ORDER BY created_at, short_url LIMIT 1`,
+		canonicalURL).Scan(&url.ShortURL, &url.OriginalURL, &url.CreatedAt, &expiresAt)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return "", storage.ErrURLNotFound
+			return storage.URL{}, storage.ErrURLNotFound
+		}
+		return storage.URL{}, queryError(ctx, "failed to get short url", err)
+	}
+	url.ExpiresAt = expiresAt.Time
+
+	return url, nil
+}
+
+func (s *PostgresStorage) GetShortURLs(ctx context.Context, canonicalURLs []string) (map[string]storage.URL, error) {
+	ctx, span := startSpan(ctx, "GetShortURLs")
+	defer span.End()
+
+	ctx, cancel := withTimeout(ctx, s.timeouts.Batch)
+	defer cancel()
+
+	rows, err := s.Db.QueryContext(ctx, `
+		SELECT DISTINCT ON (canonical_url) short_url, original_url, canonical_url, created_at, expires_at
+		FROM urls WHERE canonical_url = ANY($1)
+		ORDER BY canonical_url, created_at, short_url`,
+		pq.Array(canonicalURLs),
+	)
+	if err != nil {
+		return nil, queryError(ctx, "failed to get short urls", err)
+	}
+	defer rows.Close()
+
+	urls := make(map[string]storage.URL, len(canonicalURLs))
+	for rows.Next() {
+		var url storage.URL
+		var expiresAt sql.NullTime
+		if err := rows.Scan(&url.ShortURL, &url.OriginalURL, &url.CanonicalURL, &url.CreatedAt, &expiresAt); err != nil {
+			return nil, queryError(ctx, "failed to scan short url", err)
+		}
+		url.ExpiresAt = expiresAt.Time
+		urls[url.CanonicalURL] = url
+	}
+	if err := rows.Err(); err != nil {
+		return nil, queryError(ctx, "failed to get short urls", err)
+	}
+
+	return urls, nil
+}
+
+func (s *PostgresStorage) DeleteURL(ctx context.Context, alias string, retire bool) error {
+	ctx, span := startSpan(ctx, "DeleteURL")
+	defer span.End()
+
+	ctx, cancel := withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+
+	var deleted int
+	err := s.Db.QueryRowContext(ctx, `
+		WITH deleted AS (
+			DELETE FROM urls WHERE short_url = $1 RETURNING short_url
+		), retired AS (
+			INSERT INTO deleted_aliases (short_url)
+			SELECT short_url FROM deleted WHERE $2
+			ON CONFLICT DO NOTHING
+		), deleted_clicks AS (
+			DELETE FROM clicks WHERE short_url IN (SELECT short_url FROM deleted)
+		)
+		SELECT count(*) FROM deleted`,
+		alias, retire,
+	).Scan(&deleted)
+	if err != nil {
+		return queryError(ctx, "failed to delete url", err)
+	}
+	if deleted == 0 {
+		return storage.ErrURLNotFound
+	}
+
+	return nil
+}
+
+func (s *PostgresStorage) UpdateURL(ctx context.Context, alias string, newURL storage.URL) error {
+	ctx, span := startSpan(ctx, "UpdateURL")
+	defer span.End()
+
+	ctx, cancel := withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+
+	// An update that keeps the canonical form is always allowed and leaves
+	// is_primary as is, even if the URL has other aliases.
+	res, err := s.Db.ExecContext(ctx, `
+		UPDATE urls SET original_url = $2, canonical_url = $3,
+			is_primary = CASE WHEN canonical_url = $3 THEN is_primary ELSE true END
+		WHERE short_url = $1
+		AND (canonical_url = $3 OR NOT EXISTS (SELECT 1 FROM urls WHERE canonical_url = $3 AND short_url <> $1))`,
+		alias, newURL.OriginalURL, newURL.Canonical(),
+	)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return storage.ErrOriginalURLExists
+		}
+		return queryError(ctx, "failed to update url", err)
+	}
+
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return queryError(ctx, "failed to update url", err)
+	}
+	if updated == 0 {
+		var exists bool
+		err := s.Db.QueryRowContext(ctx,
+			"SELECT EXISTS (SELECT 1 FROM urls WHERE short_url = $1)", alias).Scan(&exists)
+		if err != nil {
+			return queryError(ctx, "failed to update url", err)
+		}
+		if exists {
+			return storage.ErrOriginalURLExists
+		}
+		return storage.ErrURLNotFound
+	}
+
+	return nil
+}
+
+// BackfillCanonicalURLs stores the canonical form of every URL saved without
+// one, oldest first. A URL whose canonical form is already taken by an older
+// URL becomes an additional alias of it.
+func (s *PostgresStorage) BackfillCanonicalURLs(ctx context.Context, canonicalize func(originalURL string) (string, error)) (int, error) {
+	ctx, span := startSpan(ctx, "BackfillCanonicalURLs")
+	defer span.End()
+
+	rows, err := s.Db.QueryContext(ctx,
+		"SELECT short_url, original_url FROM urls WHERE canonical_url IS NULL ORDER BY created_at, short_url")
+	if err != nil {
+		return 0, queryError(ctx, "failed to get urls without canonical form", err)
+	}
+	var urls []storage.URL
+	for rows.Next() {
+		var url storage.URL
+		if err := rows.Scan(&url.ShortURL, &url.OriginalURL); err != nil {
+			rows.Close()
+			return 0, queryError(ctx, "failed to scan url", err)
+		}
+		urls = append(urls, url)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, queryError(ctx, "failed to get urls without canonical form", err)
+	}
+
+	var updated int
+	for _, url := range urls {
+		canonicalURL, err := canonicalize(url.OriginalURL)
+		if err != nil {
+			// URLs saved before validation was introduced are kept as
+			// they are.
+			canonicalURL = url.OriginalURL
+		}
+
+		writeCtx, cancel := withTimeout(ctx, s.timeouts.Write)
+		res, err := s.Db.ExecContext(writeCtx, `
+			UPDATE urls SET canonical_url = $2,
+				is_primary = NOT EXISTS (SELECT 1 FROM urls WHERE canonical_url = $2 AND is_primary)
+			WHERE short_url = $1 AND canonical_url IS NULL`,
+			url.ShortURL, canonicalURL,
+		)
+		cancel()
+		if err != nil {
+			var pqErr *pq.Error
+			if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+				continue
+			}
+			return updated, queryError(ctx, "failed to update canonical url", err)
+		}
+		if n, err := res.RowsAffected(); err == nil {
+			updated += int(n)
 		}
-		return "", fmt.Errorf("failed to get short url: %w", err)
 	}
 
-	return shortURL, nil
+	return updated, nil
+}
+
+func (s *PostgresStorage) DeleteExpired(ctx context.Context, now time.Time, retire bool) (int64, error) {
+	ctx, span := startSpan(ctx, "DeleteExpired")
+	defer span.End()
+
+	ctx, cancel := withTimeout(ctx, s.timeouts.Batch)
+	defer cancel()
+
+	var deleted int64
+	err := s.Db.QueryRowContext(ctx, `
+		WITH deleted AS (
+			DELETE FROM urls WHERE expires_at <= $1 RETURNING short_url
+		), retired AS (
+			INSERT INTO deleted_aliases (short_url)
+			SELECT short_url FROM deleted WHERE $2
+			ON CONFLICT DO NOTHING
+		), deleted_clicks AS (
+			DELETE FROM clicks WHERE short_url IN (SELECT short_url FROM deleted)
+		)
+		SELECT count(*) FROM deleted`,
+		now, retire,
+	).Scan(&deleted)
+	if err != nil {
+		return 0, queryError(ctx, "failed to delete expired urls", err)
+	}
+
+	return deleted, nil
+}
+
+func (s *PostgresStorage) ForEachURL(ctx context.Context, fn func(storage.URL) error) error {
+	ctx, span := startSpan(ctx, "ForEachURL")
+	defer span.End()
+
+	rows, err := s.Db.QueryContext(ctx,
+		"SELECT short_url, original_url, created_at, expires_at FROM urls ORDER BY created_at, short_url")
+	if err != nil {
+		return queryError(ctx, "failed to list urls", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var url storage.URL
+		var expiresAt sql.NullTime
+		if err := rows.Scan(&url.ShortURL, &url.OriginalURL, &url.CreatedAt, &expiresAt); err != nil {
+			return queryError(ctx, "failed to scan url", err)
+		}
+		url.ExpiresAt = expiresAt.Time
+
+		if err := fn(url); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return queryError(ctx, "failed to list urls", err)
+	}
+
+	return nil
+}
+
+// ListURLs uses keyset pagination over the (created_at, short_url) index.
+func (s *PostgresStorage) ListURLs(ctx context.Context, query storage.ListQuery) ([]storage.URL, error) {
+	ctx, span := startSpan(ctx, "ListURLs")
+	defer span.End()
+
+	ctx, cancel := withTimeout(ctx, s.timeouts.Read)
+	defer cancel()
+
+	cmp, order := ">", "ASC"
+	if query.Descending {
+		cmp, order = "<", "DESC"
+	}
+
+	rows, err := s.Db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT short_url, original_url, created_at, expires_at
+		FROM urls
+		WHERE ($1::timestamptz IS NULL OR (created_at, short_url) %s ($1, $2))
+			AND short_url LIKE $3 || '%%'
+			AND ($4 = '' OR strpos(original_url, $4) > 0)
+		ORDER BY created_at %s, short_url %s
+		LIMIT $5`, cmp, order, order),
+		nullTime(query.AfterCreatedAt), query.AfterShortURL,
+		likePrefixReplacer.Replace(query.AliasPrefix), query.URLContains, query.Limit,
+	)
+	if err != nil {
+		return nil, queryError(ctx, "failed to list urls", err)
+	}
+	defer rows.Close()
+
+	urls := make([]storage.URL, 0, query.Limit)
+	for rows.Next() {
+		var url storage.URL
+		var expiresAt sql.NullTime
+		if err := rows.Scan(&url.ShortURL, &url.OriginalURL, &url.CreatedAt, &expiresAt); err != nil {
+			return nil, queryError(ctx, "failed to scan url", err)
+		}
+		url.ExpiresAt = expiresAt.Time
+		urls = append(urls, url)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, queryError(ctx, "failed to list urls", err)
+	}
+
+	return urls, nil
+}
+
+// SaveClicks bulk-loads clicks with COPY in a single transaction. The clicks
+// are copied into a staging table first, so that clicks of aliases deleted
+// since they were recorded are skipped, even if the alias was created again.
+func (s *PostgresStorage) SaveClicks(ctx context.Context, clicks []storage.Click) error {
+	ctx, span := startSpan(ctx, "SaveClicks")
+	defer span.End()
+
+	ctx, cancel := withTimeout(ctx, s.timeouts.Batch)
+	defer cancel()
+
+	tx, err := s.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return queryError(ctx, "failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		CREATE TEMP TABLE clicks_staging (
+			short_url TEXT NOT NULL,
+			clicked_at TIMESTAMPTZ NOT NULL,
+			referrer TEXT NOT NULL,
+			user_agent TEXT NOT NULL,
+			client_address TEXT NOT NULL
+		) ON COMMIT DROP`)
+	if err != nil {
+		return queryError(ctx, "failed to create staging table", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx,
+		pq.CopyIn("clicks_staging", "short_url", "clicked_at", "referrer", "user_agent", "client_address"))
+	if err != nil {
+		return queryError(ctx, "failed to prepare copy", err)
+	}
+
+	for _, c := range clicks {
+		_, err := stmt.ExecContext(ctx, c.ShortURL, c.ClickedAt, c.Referrer, c.UserAgent, c.ClientAddress)
+		if err != nil {
+			stmt.Close()
+			return queryError(ctx, "failed to copy click", err)
+		}
+	}
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		stmt.Close()
+		return queryError(ctx, "failed to flush clicks", err)
+	}
+	if err := stmt.Close(); err != nil {
+		return queryError(ctx, "failed to close copy", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO clicks (short_url, clicked_at, referrer, user_agent, client_address)
+		SELECT c.short_url, c.clicked_at, c.referrer, c.user_agent, c.client_address
+		FROM clicks_staging c
+		WHERE EXISTS (SELECT 1 FROM urls u WHERE u.short_url = c.short_url AND u.created_at <= c.clicked_at)`)
+	if err != nil {
+		return queryError(ctx, "failed to insert clicks", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return queryError(ctx, "failed to commit clicks", err)
+	}
+	return nil
+}
+
+func (s *PostgresStorage) GetClickStats(ctx context.Context, alias string, since time.Time) (storage.ClickStats, error) {
+	ctx, span := startSpan(ctx, "GetClickStats")
+	defer span.End()
+
+	ctx, cancel := withTimeout(ctx, s.timeouts.Read)
+	defer cancel()
+
+	var stats storage.ClickStats
+
+	err := s.Db.QueryRowContext(ctx,
+		"SELECT count(*) FROM clicks WHERE short_url = $1", alias).Scan(&stats.Total)
+	if err != nil {
+		return storage.ClickStats{}, queryError(ctx, "failed to count clicks", err)
+	}
+
+	rows, err := s.Db.QueryContext(ctx, `
+		SELECT date_trunc('day', clicked_at AT TIME ZONE 'UTC') AS day, count(*)
+		FROM clicks
+		WHERE short_url = $1 AND clicked_at >= $2
+		GROUP BY day
+		ORDER BY day`,
+		alias, since,
+	)
+	if err != nil {
+		return storage.ClickStats{}, queryError(ctx, "failed to get daily clicks", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var d storage.DailyClicks
+		if err := rows.Scan(&d.Day, &d.Clicks); err != nil {
+			return storage.ClickStats{}, queryError(ctx, "failed to scan daily clicks", err)
+		}
+		d.Day = time.Date(d.Day.Year(), d.Day.Month(), d.Day.Day(), 0, 0, 0, 0, time.UTC)
+		stats.Daily = append(stats.Daily, d)
+	}
+	if err := rows.Err(); err != nil {
+		return storage.ClickStats{}, queryError(ctx, "failed to get daily clicks", err)
+	}
+
+	return stats, nil
+}
+
+func (s *PostgresStorage) Ping(ctx context.Context) error {
+	ctx, span := startSpan(ctx, "Ping")
+	defer span.End()
+
+	ctx, cancel := withTimeout(ctx, s.timeouts.Read)
+	defer cancel()
+
+	if err := s.Db.PingContext(ctx); err != nil {
+		return queryError(ctx, "failed to ping database", err)
+	}
+	return nil
 }
 
 func (s *PostgresStorage) Close() error {
 	return s.Db.Close()
 }
+
+var likePrefixReplacer = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// startSpan starts the span of the storage operation op. Failed queries are
+// recorded on it by queryError.
+func startSpan(ctx context.Context, op string) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, "PostgresStorage."+op,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.operation.name", op),
+		))
+}
+
+// queryError wraps a failed query and records it on the current span. lib/pq
+// reports a canceled statement as a server error, so the context error is
+// attached to keep it detectable.
+func queryError(ctx context.Context, op string, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil && !errors.Is(err, ctxErr) {
+		err = fmt.Errorf("%s: %w: %w", op, ctxErr, err)
+	} else {
+		err = fmt.Errorf("%s: %w", op, err)
+	}
+	span := trace.SpanFromContext(ctx)
+	span.RecordError(err)
+	span.SetStatus(codes.Error, op)
+	return err
+}
+
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
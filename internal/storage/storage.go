@@ -1,14 +1,142 @@
 package storage
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"time"
+)
 
 var (
 	ErrURLNotFound = errors.New("url not found")
-	ErrURLExists   = errors.New("url exists")
+	ErrURLExpired  = errors.New("url expired")
+	// ErrAliasExists means the alias is taken by another URL or retired.
+	ErrAliasExists = errors.New("alias exists")
+	// ErrOriginalURLExists means the original URL is already shortened.
+	ErrOriginalURLExists = errors.New("original url exists")
 )
 
+type URL struct {
+	ShortURL    string
+	OriginalURL string // exactly as submitted, the redirect target
+	// CanonicalURL is the normalized form of OriginalURL that short URLs are
+	// deduplicated on. Storages use OriginalURL if it is empty.
+	CanonicalURL string
+	CreatedAt    time.Time
+	ExpiresAt    time.Time // zero if the URL never expires
+}
+
+// Canonical returns the canonical form of the URL, falling back to the
+// original URL.
+func (u URL) Canonical() string {
+	if u.CanonicalURL == "" {
+		return u.OriginalURL
+	}
+	return u.CanonicalURL
+}
+
+func (u URL) Expired(now time.Time) bool {
+	return !u.ExpiresAt.IsZero() && !now.Before(u.ExpiresAt)
+}
+
+type Click struct {
+	ShortURL      string
+	ClickedAt     time.Time
+	Referrer      string
+	UserAgent     string
+	ClientAddress string
+}
+
+type DailyClicks struct {
+	Day    time.Time // midnight UTC
+	Clicks int64
+}
+
+type ClickStats struct {
+	Total int64
+	Daily []DailyClicks // days with at least one click since the requested time, ascending
+}
+
+// ListQuery selects a page of URLs ordered by (CreatedAt, ShortURL). The page
+// starts right after the position given by AfterCreatedAt and AfterShortURL,
+// or at the beginning if AfterCreatedAt is zero.
+type ListQuery struct {
+	AfterCreatedAt time.Time
+	AfterShortURL  string
+	Limit          int
+	AliasPrefix    string
+	URLContains    string
+	Descending     bool
+}
+
 type URLSaverURLGetter interface {
-	SaveURL(urlToSave string, alias string) error
-	GetURL(alias string) (string, error)
-	GetShortURL(originalURL string) (string, error)
+	// SaveURL atomically inserts the mapping unless the alias or the canonical
+	// URL is taken and returns the stored URL. If the canonical URL is already
+	// shortened, the existing mapping is returned along with ErrOriginalURLExists;
+	// a taken or retired alias yields ErrAliasExists. The canonical URL conflict
+	// is reported when both apply.
+	SaveURL(ctx context.Context, url URL) (URL, error)
+	// SaveURLs saves many URLs at once. The returned slice holds nil for every
+	// saved URL and ErrOriginalURLExists or ErrAliasExists for every conflicting
+	// one, following the rules of SaveURL. A set CreatedAt is stored as is,
+	// so that imported URLs keep their creation time.
+	SaveURLs(ctx context.Context, urls []URL) ([]error, error)
+	// SaveAdditionalURL inserts the mapping even if its canonical URL is
+	// already shortened, so that an original URL may have several aliases.
+	// A taken or retired alias yields ErrAliasExists.
+	SaveAdditionalURL(ctx context.Context, url URL) (URL, error)
+	// GetURL returns ErrURLExpired for aliases that expired but were not purged yet.
+	GetURL(ctx context.Context, alias string) (string, error)
+	// GetShortURL returns the oldest URL with the given canonical form, which
+	// SaveURL reuses.
+	GetShortURL(ctx context.Context, canonicalURL string) (URL, error)
+	// GetShortURLs is GetShortURL for many canonical URLs at once. The result
+	// is keyed by canonical URL and has no entries for unknown ones.
+	GetShortURLs(ctx context.Context, canonicalURLs []string) (map[string]URL, error)
+	// DeleteURL removes the alias. A retired alias is never accepted by SaveURL again.
+	DeleteURL(ctx context.Context, alias string, retire bool) error
+	// UpdateURL replaces the original URL of the alias. Keeping the canonical
+	// form always succeeds. Otherwise ErrOriginalURLExists is returned if the new
+	// canonical form belongs to any other alias, including the additional ones
+	// saved with SaveAdditionalURL.
+	UpdateURL(ctx context.Context, alias string, newURL URL) error
+	DeleteExpired(ctx context.Context, now time.Time, retire bool) (int64, error)
+	// ForEachURL calls fn for every stored URL, including expired ones, ordered
+	// by creation time. Iteration stops at the first error returned by fn.
+	ForEachURL(ctx context.Context, fn func(URL) error) error
+	ListURLs(ctx context.Context, query ListQuery) ([]URL, error)
+}
+
+type ClickStorage interface {
+	// SaveClicks skips clicks of aliases that no longer exist or were created
+	// after the click, as the clicked alias may have been deleted, and possibly
+	// reused, while the clicks were buffered.
+	SaveClicks(ctx context.Context, clicks []Click) error
+	GetClickStats(ctx context.Context, alias string, since time.Time) (ClickStats, error)
+}
+
+// AliasPool holds pre-generated aliases, each of which is handed out once.
+type AliasPool interface {
+	// AddPoolAliases adds the aliases that are neither pooled, used nor retired
+	// and returns how many were added.
+	AddPoolAliases(ctx context.Context, aliases []string) (int, error)
+	// TakePoolAliases removes up to n aliases from the pool and returns them.
+	// Concurrent callers never get the same alias.
+	TakePoolAliases(ctx context.Context, n int) ([]string, error)
+	PoolSize(ctx context.Context) (int, error)
+}
+
+// CanonicalBackfiller is implemented by storages that may hold URLs saved
+// before canonical forms were introduced.
+type CanonicalBackfiller interface {
+	// BackfillCanonicalURLs stores the canonical form of every URL that has
+	// none and returns the number of updated URLs. A URL whose canonical form
+	// is already taken by an older URL is left without one.
+	BackfillCanonicalURLs(ctx context.Context, canonicalize func(originalURL string) (string, error)) (int, error)
+}
+
+// Pinger is implemented by storages that depend on a remote server. Storages
+// that do not implement it are always considered available.
+type Pinger interface {
+	// Ping checks that the storage is reachable.
+	Ping(ctx context.Context) error
 }
@@ -0,0 +1,213 @@
+// Package tlsconfig builds the TLS configuration of the gRPC server and of its
+// command line clients. The server picks up renewed certificates without a
+// restart.
+package tlsconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"url-shortener/internal/config"
+)
+
+// MinVersion parses a TLS version name of the configuration. The empty name
+// means TLS 1.2.
+func MinVersion(name string) (uint16, error) {
+	switch name {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported tls min_version %q, expected 1.2 or 1.3", name)
+	}
+}
+
+// Reloader serves the server certificate and the client CAs from their files
+// and reloads them once the files change.
+type Reloader struct {
+	cfg        config.TLS
+	minVersion uint16
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool // nil unless mutual TLS is enabled
+	stamps    []fileStamp    // of the loaded files
+}
+
+// fileStamp tells whether a file was changed since it was loaded.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewReloader loads the files of cfg. It fails if the certificate or the key
+// is missing or invalid.
+func NewReloader(cfg config.TLS) (*Reloader, error) {
+	if cfg.CertFile == "" || cfg.KeyFile == "" {
+		return nil, errors.New("tls cert_file and key_file are required")
+	}
+	minVersion, err := MinVersion(cfg.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Reloader{cfg: cfg, minVersion: minVersion}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Config returns the server configuration. Every handshake uses the latest
+// loaded certificate and client CAs.
+func (r *Reloader) Config() *tls.Config {
+	return &tls.Config{
+		MinVersion:         r.minVersion,
+		GetConfigForClient: r.configForClient,
+	}
+}
+
+func (r *Reloader) configForClient(*tls.ClientHelloInfo) (*tls.Config, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	cfg := &tls.Config{
+		MinVersion:   r.minVersion,
+		Certificates: []tls.Certificate{*r.cert},
+	}
+	if r.clientCAs != nil {
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+		cfg.ClientCAs = r.clientCAs
+	}
+	return cfg, nil
+}
+
+// Run checks the files every interval until ctx is done and reloads them if
+// any has changed. A failed reload keeps the previous certificate and is
+// retried on the next check.
+func (r *Reloader) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if !r.changed() {
+			continue
+		}
+		if err := r.Reload(); err != nil {
+			log.Printf("failed to reload tls certificates: %v", err)
+			continue
+		}
+		log.Printf("reloaded tls certificates from %s", r.cfg.CertFile)
+	}
+}
+
+// Reload loads the files unconditionally.
+func (r *Reloader) Reload() error {
+	// Stamps are taken first, so that a file changed while being loaded is
+	// loaded again on the next check.
+	stamps, err := r.stat()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load tls certificate: %w", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if r.cfg.ClientCAFile != "" {
+		if clientCAs, err = loadCertPool(r.cfg.ClientCAFile); err != nil {
+			return fmt.Errorf("failed to load tls client CAs: %w", err)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.stamps = stamps
+	return nil
+}
+
+func (r *Reloader) changed() bool {
+	stamps, err := r.stat()
+	if err != nil {
+		log.Printf("failed to check tls certificates: %v", err)
+		return false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for i := range stamps {
+		if !stamps[i].modTime.Equal(r.stamps[i].modTime) || stamps[i].size != r.stamps[i].size {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Reloader) stat() ([]fileStamp, error) {
+	files := []string{r.cfg.CertFile, r.cfg.KeyFile}
+	if r.cfg.ClientCAFile != "" {
+		files = append(files, r.cfg.ClientCAFile)
+	}
+
+	stamps := make([]fileStamp, len(files))
+	for i, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		stamps[i] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+	}
+	return stamps, nil
+}
+
+// ClientConfig returns the configuration of a client that trusts the CAs in
+// caFile, or the system roots if it is empty, and presents the certificate in
+// certFile and keyFile if they are set.
+func ClientConfig(caFile, certFile, keyFile string) (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile != "" {
+		pool, err := loadCertPool(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load tls CAs: %w", err)
+		}
+		cfg.RootCAs = pool
+	}
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load tls client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+func loadCertPool(file string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", file)
+	}
+	return pool, nil
+}
@@ -0,0 +1,99 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"url-shortener/internal/health"
+	"url-shortener/internal/storage"
+	"url-shortener/internal/storage/memory"
+)
+
+// pingingStorage is an in-memory storage whose ping fails while down is set.
+type pingingStorage struct {
+	*memory.MemoryStorage
+	down bool
+}
+
+func (s *pingingStorage) Ping(ctx context.Context) error {
+	if s.down {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+var _ storage.Pinger = (*pingingStorage)(nil)
+
+func TestHealth_InMemory(t *testing.T) {
+	store := &pingingStorage{MemoryStorage: memory.New()}
+	checker := health.NewChecker(store, time.Second)
+
+	s := grpc.NewServer()
+	checker.Register(s)
+	lis, _ := newBufConnListener(t, s)
+	defer s.GracefulStop()
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithDialer(func(string, time.Duration) (net.Conn, error) {
+			return lis.Dial()
+		}), grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("Failed to dial bufnet: %v", err)
+	}
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	expect := func(stage string, want healthpb.HealthCheckResponse_ServingStatus) {
+		t.Helper()
+		for _, service := range []string{"", "url_shortener.URLShortener"} {
+			resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+			if err != nil {
+				t.Fatalf("%s: Check(%q) failed: %v", stage, service, err)
+			}
+			if resp.Status != want {
+				t.Errorf("%s: Expected %q to be %v, got %v", stage, service, want, resp.Status)
+			}
+		}
+
+		rec := httptest.NewRecorder()
+		checker.ReadinessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		wantCode := http.StatusOK
+		if want != healthpb.HealthCheckResponse_SERVING {
+			wantCode = http.StatusServiceUnavailable
+		}
+		if rec.Code != wantCode {
+			t.Errorf("%s: Expected /readyz to answer %d, got %d", stage, wantCode, rec.Code)
+		}
+
+		rec = httptest.NewRecorder()
+		checker.LivenessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s: Expected /healthz to answer 200, got %d", stage, rec.Code)
+		}
+	}
+
+	expect("before the first check", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	checker.Check(context.Background())
+	expect("storage up", healthpb.HealthCheckResponse_SERVING)
+
+	store.down = true
+	checker.Check(context.Background())
+	expect("storage down", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	store.down = false
+	checker.Check(context.Background())
+	expect("storage back up", healthpb.HealthCheckResponse_SERVING)
+
+	checker.Shutdown()
+	checker.Check(context.Background())
+	expect("shutting down", healthpb.HealthCheckResponse_NOT_SERVING)
+}
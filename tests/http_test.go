@@ -0,0 +1,158 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"url-shortener/internal/config"
+	"url-shortener/internal/httpserver"
+	"url-shortener/internal/service"
+	"url-shortener/internal/storage/memory"
+)
+
+func newTestHTTPClient() *http.Client {
+	return &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func TestRedirect_InMemory(t *testing.T) {
+	cfg := config.MustLoad()
+	memStorage := memory.New()
+	testService := newTestService(t, memStorage, *cfg)
+
+	url, err := testService.CreateShortURL(context.Background(), "https://example.com/page", "", time.Time{}, service.DedupeReuse)
+	if err != nil {
+		t.Fatalf("CreateShortURL failed: %v", err)
+	}
+	shortURL := url.ShortURL
+
+	tests := []struct {
+		name      string
+		method    string
+		permanent bool
+		alias     string
+		code      int
+		location  string
+	}{
+		{name: "temporary redirect", method: http.MethodGet, alias: shortURL, code: http.StatusFound, location: "https://example.com/page"},
+		{name: "permanent redirect", method: http.MethodGet, permanent: true, alias: shortURL, code: http.StatusMovedPermanently, location: "https://example.com/page"},
+		{name: "head", method: http.MethodHead, alias: shortURL, code: http.StatusFound, location: "https://example.com/page"},
+		{name: "not found", method: http.MethodGet, alias: "missing", code: http.StatusNotFound},
+		{name: "head not found", method: http.MethodHead, alias: "missing", code: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(httpserver.NewHandler(testService, tt.permanent))
+			defer ts.Close()
+
+			req, err := http.NewRequest(tt.method, ts.URL+"/"+tt.alias, nil)
+			if err != nil {
+				t.Fatalf("failed to build request: %v", err)
+			}
+			resp, err := newTestHTTPClient().Do(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.code {
+				t.Errorf("Expected status %d, got %d", tt.code, resp.StatusCode)
+			}
+			if got := resp.Header.Get("Location"); got != tt.location {
+				t.Errorf("Expected Location %q, got %q", tt.location, got)
+			}
+		})
+	}
+}
+
+func TestRESTAPI_InMemory(t *testing.T) {
+	cfg := config.MustLoad()
+	memStorage := memory.New()
+	testService := newTestService(t, memStorage, *cfg)
+
+	ts := httptest.NewServer(httpserver.NewHandler(testService, false))
+	defer ts.Close()
+
+	resp, err := http.Post(ts.URL+"/api/v1/urls", "application/json",
+		strings.NewReader(`{"original_url": "https://example.com", "custom_alias": "rest"}`))
+	if err != nil {
+		t.Fatalf("create request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	resp, err = http.Get(ts.URL + "/api/v1/urls/rest")
+	if err != nil {
+		t.Fatalf("get request failed: %v", err)
+	}
+	var got struct {
+		ShortURL    string `json:"short_url"`
+		OriginalURL string `json:"original_url"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&got)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if got.OriginalURL != "https://example.com" || got.ShortURL != "rest" {
+		t.Errorf("unexpected response: %+v", got)
+	}
+
+	errorCases := []struct {
+		name string
+		do   func() (*http.Response, error)
+		code int
+	}{
+		{
+			name: "alias taken",
+			do: func() (*http.Response, error) {
+				return http.Post(ts.URL+"/api/v1/urls", "application/json",
+					strings.NewReader(`{"original_url": "https://example.org", "custom_alias": "rest"}`))
+			},
+			code: http.StatusConflict,
+		},
+		{
+			name: "empty original url",
+			do: func() (*http.Response, error) {
+				return http.Post(ts.URL+"/api/v1/urls", "application/json", strings.NewReader(`{}`))
+			},
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "malformed body",
+			do: func() (*http.Response, error) {
+				return http.Post(ts.URL+"/api/v1/urls", "application/json", strings.NewReader(`{`))
+			},
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "not found",
+			do: func() (*http.Response, error) {
+				return http.Get(ts.URL + "/api/v1/urls/missing")
+			},
+			code: http.StatusNotFound,
+		},
+	}
+	for _, tt := range errorCases {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := tt.do()
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.code {
+				t.Errorf("Expected status %d, got %d", tt.code, resp.StatusCode)
+			}
+		})
+	}
+}
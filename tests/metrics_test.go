@@ -0,0 +1,57 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+
+	"url-shortener/internal/config"
+	mygrpc "url-shortener/internal/grpc"
+	"url-shortener/internal/metrics"
+	"url-shortener/internal/service"
+	"url-shortener/internal/storage/memory"
+)
+
+func TestMetrics_InMemory(t *testing.T) {
+	cfg := config.MustLoad()
+
+	s := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(metrics.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(metrics.StreamServerInterceptor()),
+	)
+	testService := service.NewURLShortenerService(metrics.NewStorage("memory", memory.New()), cfg.ShortURLLength)
+	mygrpc.RegisterURLShortenerServer(s, mygrpc.NewURLShortenerServer(testService))
+	lis, _ := newBufConnListener(t, s)
+	client, close := newTestClient(t, lis)
+	defer close()
+	defer s.GracefulStop()
+
+	resp, err := client.CreateShortURL(context.Background(), &mygrpc.CreateShortURLRequest{OriginalUrl: "https://example.com/metrics"})
+	if err != nil {
+		t.Fatalf("CreateShortURL failed: %v", err)
+	}
+	if _, err := client.GetOriginalURL(context.Background(), &mygrpc.GetOriginalURLRequest{ShortUrl: resp.ShortUrl + "x"}); err == nil {
+		t.Fatalf("Expected GetOriginalURL of an unknown alias to fail")
+	}
+
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+	for _, want := range []string{
+		`grpc_server_handled_total{grpc_code="OK",grpc_method="/url_shortener.URLShortener/CreateShortURL"}`,
+		`grpc_server_handled_total{grpc_code="NotFound",grpc_method="/url_shortener.URLShortener/GetOriginalURL"}`,
+		`grpc_server_handling_seconds_count{grpc_method="/url_shortener.URLShortener/CreateShortURL"}`,
+		`storage_operation_seconds_count{backend="memory",operation="save_url",result="ok"}`,
+		`storage_operation_seconds_count{backend="memory",operation="get_url",result="not_found"}`,
+		`alias_inserts_total`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("Expected metrics to contain %s", want)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package tests
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/test/bufconn"
+
+	"url-shortener/internal/auth"
+	"url-shortener/internal/config"
+	mygrpc "url-shortener/internal/grpc"
+	"url-shortener/internal/storage/memory"
+	"url-shortener/internal/tlsconfig"
+)
+
+// testCA issues certificates for the TLS tests.
+type testCA struct {
+	cert   *x509.Certificate
+	key    *ecdsa.PrivateKey
+	serial int64
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate CA key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create CA certificate: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCA{cert: cert, key: key, serial: 1}
+}
+
+// issue writes a certificate for commonName and its key to dir and returns
+// their paths.
+func (ca *testCA) issue(t *testing.T, dir string, commonName string, usage x509.ExtKeyUsage) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	ca.serial++
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(ca.serial),
+		Subject:      pkix.Name{CommonName: commonName, Organization: []string{"url-shortener"}},
+		DNSNames:     []string{commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	certFile = filepath.Join(dir, commonName+".crt")
+	keyFile = filepath.Join(dir, commonName+".key")
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+	return certFile, keyFile
+}
+
+func (ca *testCA) writeCert(t *testing.T, file string) {
+	t.Helper()
+	writePEM(t, file, "CERTIFICATE", ca.cert.Raw)
+}
+
+func writePEM(t *testing.T, file string, blockType string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatalf("Failed to write %s: %v", file, err)
+	}
+}
+
+func dialTLS(t *testing.T, lis *bufconn.Listener, tlsConfig *tls.Config) mygrpc.URLShortenerClient {
+	t.Helper()
+	tlsConfig.ServerName = "localhost"
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithDialer(func(string, time.Duration) (net.Conn, error) {
+			return lis.Dial()
+		}), grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	if err != nil {
+		t.Fatalf("Failed to dial bufnet: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return mygrpc.NewURLShortenerClient(conn)
+}
+
+func TestMutualTLS_InMemory(t *testing.T) {
+	cfg := config.MustLoad()
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	caFile := filepath.Join(dir, "ca.crt")
+	ca.writeCert(t, caFile)
+	serverCert, serverKey := ca.issue(t, dir, "localhost", x509.ExtKeyUsageServerAuth)
+	clientCert, clientKey := ca.issue(t, dir, "importer", x509.ExtKeyUsageClientAuth)
+
+	reloader, err := tlsconfig.NewReloader(config.TLS{
+		CertFile:     serverCert,
+		KeyFile:      serverKey,
+		MinVersion:   "1.3",
+		ClientCAFile: caFile,
+	})
+	if err != nil {
+		t.Fatalf("Failed to load tls certificates: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go reloader.Run(ctx, 10*time.Millisecond)
+
+	identities := make(chan auth.Identity, 10)
+	s := grpc.NewServer(
+		grpc.Creds(credentials.NewTLS(reloader.Config())),
+		grpc.ChainUnaryInterceptor(auth.UnaryServerInterceptor(),
+			func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+				if identity, ok := auth.IdentityFromContext(ctx); ok {
+					identities <- identity
+				}
+				return handler(ctx, req)
+			}),
+	)
+	mygrpc.RegisterURLShortenerServer(s, mygrpc.NewURLShortenerServer(newTestService(t, memory.New(), *cfg)))
+	lis, _ := newBufConnListener(t, s)
+	defer s.GracefulStop()
+
+	clientConfig, err := tlsconfig.ClientConfig(caFile, clientCert, clientKey)
+	if err != nil {
+		t.Fatalf("Failed to load client certificate: %v", err)
+	}
+
+	// create shortens a URL on a new connection and returns the certificate
+	// presented by the server.
+	create := func(t *testing.T) *x509.Certificate {
+		t.Helper()
+		var p peer.Peer
+		client := dialTLS(t, lis, clientConfig.Clone())
+		_, err := client.CreateShortURL(context.Background(),
+			&mygrpc.CreateShortURLRequest{OriginalUrl: "https://example.com/tls"}, grpc.Peer(&p))
+		if err != nil {
+			t.Fatalf("CreateShortURL over mutual TLS failed: %v", err)
+		}
+		tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+		if !ok || len(tlsInfo.State.PeerCertificates) == 0 {
+			t.Fatalf("Expected the connection to use TLS, got %v", p.AuthInfo)
+		}
+		return tlsInfo.State.PeerCertificates[0]
+	}
+
+	create(t)
+	select {
+	case identity := <-identities:
+		if identity.CommonName != "importer" || len(identity.DNSNames) != 1 || identity.DNSNames[0] != "importer" {
+			t.Errorf("Expected the identity of the importer certificate, got %+v", identity)
+		}
+	default:
+		t.Errorf("Expected the client identity in the request context")
+	}
+
+	t.Run("client without certificate", func(t *testing.T) {
+		noCertConfig, err := tlsconfig.ClientConfig(caFile, "", "")
+		if err != nil {
+			t.Fatalf("Failed to load CA: %v", err)
+		}
+		client := dialTLS(t, lis, noCertConfig)
+		_, err = client.CreateShortURL(context.Background(), &mygrpc.CreateShortURLRequest{OriginalUrl: "https://example.com/tls"})
+		if err == nil {
+			t.Errorf("Expected a client without certificate to be rejected")
+		}
+	})
+
+	t.Run("certificate reload", func(t *testing.T) {
+		// Replace the served certificate in place, as a renewal would.
+		renewedCert, renewedKey := ca.issue(t, t.TempDir(), "localhost", x509.ExtKeyUsageServerAuth)
+		for _, f := range [][2]string{{renewedCert, serverCert}, {renewedKey, serverKey}} {
+			data, err := os.ReadFile(f[0])
+			if err != nil {
+				t.Fatalf("Failed to read %s: %v", f[0], err)
+			}
+			if err := os.WriteFile(f[1], data, 0o600); err != nil {
+				t.Fatalf("Failed to write %s: %v", f[1], err)
+			}
+			future := time.Now().Add(time.Minute)
+			if err := os.Chtimes(f[1], future, future); err != nil {
+				t.Fatalf("Failed to touch %s: %v", f[1], err)
+			}
+		}
+		renewed, err := tls.LoadX509KeyPair(renewedCert, renewedKey)
+		if err != nil {
+			t.Fatalf("Failed to load renewed certificate: %v", err)
+		}
+		renewedLeaf, _ := x509.ParseCertificate(renewed.Certificate[0])
+
+		deadline := time.Now().Add(2 * time.Second)
+		for create(t).SerialNumber.Cmp(renewedLeaf.SerialNumber) != 0 {
+			if time.Now().After(deadline) {
+				t.Fatalf("Expected the renewed certificate to be served")
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	})
+}
@@ -0,0 +1,94 @@
+package tests
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"url-shortener/internal/config"
+	mygrpc "url-shortener/internal/grpc"
+	"url-shortener/internal/service"
+	"url-shortener/internal/storage/memory"
+	"url-shortener/internal/tracing"
+)
+
+func TestTracing_InMemory(t *testing.T) {
+	cfg := config.MustLoad()
+	traceFile := filepath.Join(t.TempDir(), "traces.jsonl")
+	shutdown, err := tracing.Setup(context.Background(), config.Tracing{
+		Exporter:    "file",
+		File:        traceFile,
+		SampleRatio: 1,
+		ServiceName: "url-shortener-test",
+	})
+	if err != nil {
+		t.Fatalf("Failed to set up tracing: %v", err)
+	}
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	s := grpc.NewServer(grpc.StatsHandler(otelgrpc.NewServerHandler()))
+	testService := service.NewURLShortenerService(memory.New(), cfg.ShortURLLength)
+	mygrpc.RegisterURLShortenerServer(s, mygrpc.NewURLShortenerServer(testService))
+	lis, _ := newBufConnListener(t, s)
+	client, close := newTestClient(t, lis)
+	defer close()
+	defer s.GracefulStop()
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	ctx := metadata.AppendToOutgoingContext(context.Background(),
+		"traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	if _, err := client.CreateShortURL(ctx, &mygrpc.CreateShortURLRequest{OriginalUrl: "https://example.com/traced"}); err != nil {
+		t.Fatalf("CreateShortURL failed: %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("Failed to shut down tracing: %v", err)
+	}
+
+	f, err := os.Open(traceFile)
+	if err != nil {
+		t.Fatalf("Failed to open trace file: %v", err)
+	}
+	defer f.Close()
+
+	spans := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		var span struct {
+			Name        string
+			SpanContext struct{ TraceID string }
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &span); err != nil {
+			t.Fatalf("Failed to decode span: %v", err)
+		}
+		spans[span.Name] = span.SpanContext.TraceID
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("Failed to read trace file: %v", err)
+	}
+
+	for _, name := range []string{
+		"url_shortener.URLShortener/CreateShortURL",
+		"URLShortenerService.CreateShortURL",
+		"URLShortenerService.generateAlias",
+		"URLShortenerService.saveURL",
+	} {
+		got, ok := spans[name]
+		if !ok {
+			t.Errorf("Expected span %s to be exported, got %v", name, spans)
+			continue
+		}
+		if got != traceID {
+			t.Errorf("Expected span %s to continue trace %s, got %s", name, traceID, got)
+		}
+	}
+}
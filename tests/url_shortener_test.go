@@ -2,18 +2,28 @@ package tests
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"math"
 	"net"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"url-shortener/internal/alias"
+	"url-shortener/internal/analytics"
 	"url-shortener/internal/config"
 	mygrpc "url-shortener/internal/grpc"
 	"url-shortener/internal/service"
@@ -42,7 +52,7 @@ func newTestPostgresStorage(t *testing.T, cfg config.Config) *postgres.PostgresS
 }
 
 func cleanDatabase(pgStorage *postgres.PostgresStorage) error {
-	_, err := pgStorage.Db.Exec("DELETE FROM urls")
+	_, err := pgStorage.Db.Exec("DELETE FROM urls; DELETE FROM deleted_aliases; DELETE FROM clicks; DELETE FROM alias_pool")
 	return err
 }
 
@@ -124,7 +134,7 @@ func TestCreateShortURL_Postgres(t *testing.T) {
 		t.Errorf("ShortURL should not be empty")
 	}
 
-	url, err := pgStorage.GetURL(resp.ShortUrl)
+	url, err := pgStorage.GetURL(context.Background(), resp.ShortUrl)
 	if err != nil {
 		t.Fatalf("Failed to get URL from storage: %v", err)
 	}
@@ -149,7 +159,7 @@ func TestGetOriginalURL_Postgres(t *testing.T) {
 	originalURL := "https://example.com"
 	shortURL := "test"
 
-	err := pgStorage.SaveURL(originalURL, shortURL)
+	_, err := pgStorage.SaveURL(context.Background(), storage.URL{ShortURL: shortURL, OriginalURL: originalURL})
 	if err != nil {
 		t.Fatalf("Failed to save url to database %v", err)
 	}
@@ -190,7 +200,7 @@ func TestCreateShortURL_CustomAliasAlreadyExists_Postgres(t *testing.T) {
 	originalURL := "https://example.com"
 	shortURL := "existing"
 
-	err := pgStorage.SaveURL(originalURL, shortURL)
+	_, err := pgStorage.SaveURL(context.Background(), storage.URL{ShortURL: shortURL, OriginalURL: originalURL})
 	if err != nil {
 		t.Fatalf("Failed to save url to database %v", err)
 	}
@@ -250,7 +260,7 @@ func TestCreateShortURL_InMemory(t *testing.T) {
 		t.Errorf("ShortURL should not be empty")
 	}
 
-	url, err := memStorage.GetURL(resp.ShortUrl)
+	url, err := memStorage.GetURL(context.Background(), resp.ShortUrl)
 	if err != nil {
 		t.Fatalf("Failed to get URL from storage: %v", err)
 	}
@@ -270,7 +280,7 @@ func TestGetOriginalURL_InMemory(t *testing.T) {
 	originalURL := "https://example.com"
 	shortURL := "test"
 
-	err := memStorage.SaveURL(originalURL, shortURL)
+	_, err := memStorage.SaveURL(context.Background(), storage.URL{ShortURL: shortURL, OriginalURL: originalURL})
 	if err != nil {
 		t.Fatalf("Failed to save url to memory storage %v", err)
 	}
@@ -308,7 +318,7 @@ func TestCreateShortURL_CustomAliasAlreadyExists_InMemory(t *testing.T) {
 	originalURL := "https://example.com"
 	shortURL := "existing"
 
-	err := memStorage.SaveURL(originalURL, shortURL)
+	_, err := memStorage.SaveURL(context.Background(), storage.URL{ShortURL: shortURL, OriginalURL: originalURL})
 	if err != nil {
 		t.Fatalf("Failed to save url to memory storage %v", err)
 	}
@@ -348,7 +358,7 @@ type mockStorage struct {
 
 func (m *mockStorage) SaveURL(urlToSave string, alias string) error {
 	if _, ok := m.data[alias]; ok {
-		return storage.ErrURLExists
+		return storage.ErrAliasExists
 	}
 
 	m.data[alias] = urlToSave
@@ -362,3 +372,1617 @@ func (m *mockStorage) GetURL(alias string) (string, error) {
 	}
 	return url, nil
 }
+
+func TestDeleteShortURL_InMemory(t *testing.T) {
+	cfg := config.MustLoad()
+
+	memStorage := memory.New()
+
+	s := newTestGRPCServer(t, memStorage, *cfg)
+	lis, errChan := newBufConnListener(t, s)
+	client, close := newTestClient(t, lis)
+	defer close()
+	defer s.GracefulStop()
+	select {
+	case err := <-errChan:
+		t.Fatalf("gRPC server failed: %v", err)
+	default:
+	}
+
+	ctx := context.Background()
+	_, err := client.CreateShortURL(ctx, &mygrpc.CreateShortURLRequest{OriginalUrl: "https://example.com", CustomAlias: "deleted"})
+	if err != nil {
+		t.Fatalf("CreateShortURL failed: %v", err)
+	}
+
+	if _, err := client.DeleteShortURL(ctx, &mygrpc.DeleteShortURLRequest{ShortUrl: "deleted"}); err != nil {
+		t.Fatalf("DeleteShortURL failed: %v", err)
+	}
+
+	_, err = client.GetOriginalURL(ctx, &mygrpc.GetOriginalURLRequest{ShortUrl: "deleted"})
+	if st, _ := status.FromError(err); st.Code() != codes.NotFound {
+		t.Errorf("Expected code to be %s, got %s", codes.NotFound, st.Code())
+	}
+
+	_, err = client.DeleteShortURL(ctx, &mygrpc.DeleteShortURLRequest{ShortUrl: "deleted"})
+	if st, _ := status.FromError(err); st.Code() != codes.NotFound {
+		t.Errorf("Expected code to be %s, got %s", codes.NotFound, st.Code())
+	}
+
+	// Deleted aliases are retired unless reuse is enabled.
+	_, err = client.CreateShortURL(ctx, &mygrpc.CreateShortURLRequest{OriginalUrl: "https://example.org", CustomAlias: "deleted"})
+	if st, _ := status.FromError(err); st.Code() != codes.AlreadyExists {
+		t.Errorf("Expected code to be %s, got %s", codes.AlreadyExists, st.Code())
+	}
+
+	// The original URL itself is free to be shortened again.
+	resp, err := client.CreateShortURL(ctx, &mygrpc.CreateShortURLRequest{OriginalUrl: "https://example.com"})
+	if err != nil {
+		t.Fatalf("CreateShortURL failed: %v", err)
+	}
+	if resp.ShortUrl == "deleted" {
+		t.Errorf("retired alias must not be reused")
+	}
+}
+
+func TestDeleteShortURL_AliasReuse_InMemory(t *testing.T) {
+	cfg := config.MustLoad()
+
+	memStorage := memory.New()
+	testService := service.NewURLShortenerService(memStorage, cfg.ShortURLLength, service.WithAliasReuse(true))
+
+	ctx := context.Background()
+	if _, err := testService.CreateShortURL(ctx, "https://example.com", "reused", time.Time{}, service.DedupeReuse); err != nil {
+		t.Fatalf("CreateShortURL failed: %v", err)
+	}
+	if err := testService.DeleteShortURL(ctx, "reused"); err != nil {
+		t.Fatalf("DeleteShortURL failed: %v", err)
+	}
+
+	url, err := testService.CreateShortURL(ctx, "https://example.org", "reused", time.Time{}, service.DedupeReuse)
+	if err != nil {
+		t.Fatalf("CreateShortURL failed: %v", err)
+	}
+	if url.ShortURL != "reused" {
+		t.Errorf("Expected alias to be reused, got %s", url.ShortURL)
+	}
+}
+
+func TestUpdateShortURL_InMemory(t *testing.T) {
+	cfg := config.MustLoad()
+
+	memStorage := memory.New()
+
+	s := newTestGRPCServer(t, memStorage, *cfg)
+	lis, errChan := newBufConnListener(t, s)
+	client, close := newTestClient(t, lis)
+	defer close()
+	defer s.GracefulStop()
+	select {
+	case err := <-errChan:
+		t.Fatalf("gRPC server failed: %v", err)
+	default:
+	}
+
+	ctx := context.Background()
+	if _, err := memStorage.SaveURL(ctx, storage.URL{ShortURL: "promo", OriginalURL: "https://example.com"}); err != nil {
+		t.Fatalf("Failed to save url to memory storage %v", err)
+	}
+	if _, err := memStorage.SaveURL(ctx, storage.URL{ShortURL: "other", OriginalURL: "https://example.net"}); err != nil {
+		t.Fatalf("Failed to save url to memory storage %v", err)
+	}
+
+	updated, err := client.UpdateShortURL(ctx, &mygrpc.UpdateShortURLRequest{ShortUrl: "promo", OriginalUrl: "  https://example.org "})
+	if err != nil {
+		t.Fatalf("UpdateShortURL failed: %v", err)
+	}
+	if updated.ShortUrl != "promo" || updated.OriginalUrl != "https://example.org" {
+		t.Errorf("Expected the stored url promo -> https://example.org, got %s -> %q", updated.ShortUrl, updated.OriginalUrl)
+	}
+
+	resp, err := client.GetOriginalURL(ctx, &mygrpc.GetOriginalURLRequest{ShortUrl: "promo"})
+	if err != nil {
+		t.Fatalf("GetOriginalURL failed: %v", err)
+	}
+	if resp.OriginalUrl != "https://example.org" {
+		t.Errorf("OriginalURL should be https://example.org, but got %s", resp.OriginalUrl)
+	}
+
+	// The previous target is no longer shortened and can get a new alias.
+	if _, err := memStorage.GetShortURL(ctx, "https://example.com"); !errors.Is(err, storage.ErrURLNotFound) {
+		t.Errorf("Expected previous target to be released, got %v", err)
+	}
+
+	_, err = client.UpdateShortURL(ctx, &mygrpc.UpdateShortURLRequest{ShortUrl: "promo", OriginalUrl: "https://example.net"})
+	if st, _ := status.FromError(err); st.Code() != codes.AlreadyExists {
+		t.Errorf("Expected code to be %s, got %s", codes.AlreadyExists, st.Code())
+	}
+
+	// An update that keeps the canonical form succeeds even if the URL has
+	// additional aliases.
+	if _, err := memStorage.SaveAdditionalURL(ctx, storage.URL{ShortURL: "promo2", OriginalURL: "https://example.org"}); err != nil {
+		t.Fatalf("Failed to save url to memory storage %v", err)
+	}
+	for _, alias := range []string{"promo", "promo2"} {
+		updated, err := client.UpdateShortURL(ctx, &mygrpc.UpdateShortURLRequest{ShortUrl: alias, OriginalUrl: "https://EXAMPLE.org"})
+		if err != nil {
+			t.Errorf("Expected the update of %s keeping the canonical url to succeed, got %v", alias, err)
+		} else if updated.OriginalUrl != "https://EXAMPLE.org" {
+			t.Errorf("Expected the stored url https://EXAMPLE.org, got %q", updated.OriginalUrl)
+		}
+	}
+
+	// Additional aliases block a retarget just like the primary ones.
+	if _, err := memStorage.SaveAdditionalURL(ctx, storage.URL{ShortURL: "campaign", OriginalURL: "https://example.edu"}); err != nil {
+		t.Fatalf("Failed to save url to memory storage %v", err)
+	}
+	_, err = client.UpdateShortURL(ctx, &mygrpc.UpdateShortURLRequest{ShortUrl: "other", OriginalUrl: "https://example.edu"})
+	if st, _ := status.FromError(err); st.Code() != codes.AlreadyExists {
+		t.Errorf("Expected code to be %s, got %s", codes.AlreadyExists, st.Code())
+	}
+
+	_, err = client.UpdateShortURL(ctx, &mygrpc.UpdateShortURLRequest{ShortUrl: "missing", OriginalUrl: "https://example.com"})
+	if st, _ := status.FromError(err); st.Code() != codes.NotFound {
+		t.Errorf("Expected code to be %s, got %s", codes.NotFound, st.Code())
+	}
+}
+
+func TestUpdateShortURL_Postgres(t *testing.T) {
+	cfg := config.MustLoad()
+	pgStorage := newTestPostgresStorage(t, *cfg)
+	defer func() {
+		if err := pgStorage.Close(); err != nil {
+			t.Fatalf("failed to close database connection: %v", err)
+		}
+	}()
+
+	s := newTestGRPCServer(t, pgStorage, *cfg)
+	lis, errChan := newBufConnListener(t, s)
+	client, close := newTestClient(t, lis)
+	defer close()
+	defer s.GracefulStop()
+	select {
+	case err := <-errChan:
+		t.Fatalf("gRPC server failed: %v", err)
+	default:
+	}
+
+	ctx := context.Background()
+	if _, err := pgStorage.SaveURL(ctx, storage.URL{ShortURL: "promo", OriginalURL: "https://example.com"}); err != nil {
+		t.Fatalf("Failed to save url to database %v", err)
+	}
+	if _, err := pgStorage.SaveURL(ctx, storage.URL{ShortURL: "other", OriginalURL: "https://example.net"}); err != nil {
+		t.Fatalf("Failed to save url to database %v", err)
+	}
+
+	updated, err := client.UpdateShortURL(ctx, &mygrpc.UpdateShortURLRequest{ShortUrl: "promo", OriginalUrl: "https://example.org"})
+	if err != nil {
+		t.Fatalf("UpdateShortURL failed: %v", err)
+	}
+	if updated.OriginalUrl != "https://example.org" {
+		t.Errorf("Expected the stored url https://example.org, got %q", updated.OriginalUrl)
+	}
+	if url, err := pgStorage.GetURL(ctx, "promo"); err != nil || url != "https://example.org" {
+		t.Errorf("Expected promo to point to https://example.org, got %q, %v", url, err)
+	}
+	// The previous target is no longer shortened and can get a new alias.
+	if _, err := pgStorage.GetShortURL(ctx, "https://example.com"); !errors.Is(err, storage.ErrURLNotFound) {
+		t.Errorf("Expected previous target to be released, got %v", err)
+	}
+
+	_, err = client.UpdateShortURL(ctx, &mygrpc.UpdateShortURLRequest{ShortUrl: "promo", OriginalUrl: "https://example.net"})
+	if st, _ := status.FromError(err); st.Code() != codes.AlreadyExists {
+		t.Errorf("Expected code to be %s, got %s", codes.AlreadyExists, st.Code())
+	}
+
+	// An update that keeps the canonical form succeeds even if the URL has
+	// additional aliases, and does not make an additional alias primary.
+	if _, err := pgStorage.SaveAdditionalURL(ctx, storage.URL{ShortURL: "promo2", OriginalURL: "https://example.org"}); err != nil {
+		t.Fatalf("Failed to save url to database %v", err)
+	}
+	for _, alias := range []string{"promo", "promo2"} {
+		if _, err := client.UpdateShortURL(ctx, &mygrpc.UpdateShortURLRequest{ShortUrl: alias, OriginalUrl: "https://EXAMPLE.org"}); err != nil {
+			t.Errorf("Expected the update of %s keeping the canonical url to succeed, got %v", alias, err)
+		}
+	}
+	var primary string
+	if err := pgStorage.Db.QueryRow("SELECT short_url FROM urls WHERE canonical_url = $1 AND is_primary", "https://example.org").Scan(&primary); err != nil || primary != "promo" {
+		t.Errorf("Expected promo to stay the only primary alias, got %q, %v", primary, err)
+	}
+
+	// Additional aliases block a retarget just like the primary ones.
+	if _, err := pgStorage.SaveAdditionalURL(ctx, storage.URL{ShortURL: "campaign", OriginalURL: "https://example.edu"}); err != nil {
+		t.Fatalf("Failed to save url to database %v", err)
+	}
+	_, err = client.UpdateShortURL(ctx, &mygrpc.UpdateShortURLRequest{ShortUrl: "other", OriginalUrl: "https://example.edu"})
+	if st, _ := status.FromError(err); st.Code() != codes.AlreadyExists {
+		t.Errorf("Expected code to be %s, got %s", codes.AlreadyExists, st.Code())
+	}
+
+	_, err = client.UpdateShortURL(ctx, &mygrpc.UpdateShortURLRequest{ShortUrl: "missing", OriginalUrl: "https://example.com"})
+	if st, _ := status.FromError(err); st.Code() != codes.NotFound {
+		t.Errorf("Expected code to be %s, got %s", codes.NotFound, st.Code())
+	}
+}
+
+func TestShortURLExpiration_InMemory(t *testing.T) {
+	cfg := config.MustLoad()
+
+	memStorage := memory.New()
+	testService := newTestService(t, memStorage, *cfg)
+
+	s := grpc.NewServer()
+	mygrpc.RegisterURLShortenerServer(s, mygrpc.NewURLShortenerServer(testService))
+	lis, errChan := newBufConnListener(t, s)
+	client, close := newTestClient(t, lis)
+	defer close()
+	defer s.GracefulStop()
+	select {
+	case err := <-errChan:
+		t.Fatalf("gRPC server failed: %v", err)
+	default:
+	}
+
+	ctx := context.Background()
+	_, err := client.CreateShortURL(ctx, &mygrpc.CreateShortURLRequest{
+		OriginalUrl: "https://example.com",
+		Ttl:         durationpb.New(time.Minute),
+		ExpiresAt:   timestamppb.New(time.Now().Add(time.Minute)),
+	})
+	if st, _ := status.FromError(err); st.Code() != codes.InvalidArgument {
+		t.Errorf("Expected code to be %s, got %s", codes.InvalidArgument, st.Code())
+	}
+
+	resp, err := client.CreateShortURL(ctx, &mygrpc.CreateShortURLRequest{
+		OriginalUrl: "https://example.com",
+		Ttl:         durationpb.New(50 * time.Millisecond),
+	})
+	if err != nil {
+		t.Fatalf("CreateShortURL failed: %v", err)
+	}
+	if resp.ExpiresAt == nil {
+		t.Fatalf("ExpiresAt should be set")
+	}
+
+	if _, err := client.GetOriginalURL(ctx, &mygrpc.GetOriginalURLRequest{ShortUrl: resp.ShortUrl}); err != nil {
+		t.Fatalf("GetOriginalURL failed: %v", err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	_, err = client.GetOriginalURL(ctx, &mygrpc.GetOriginalURLRequest{ShortUrl: resp.ShortUrl})
+	if st, _ := status.FromError(err); st.Code() != codes.FailedPrecondition {
+		t.Errorf("Expected code to be %s, got %s", codes.FailedPrecondition, st.Code())
+	}
+
+	purged, err := testService.PurgeExpiredURLs(ctx)
+	if err != nil {
+		t.Fatalf("PurgeExpiredURLs failed: %v", err)
+	}
+	if purged != 1 {
+		t.Errorf("Expected 1 purged url, got %d", purged)
+	}
+
+	_, err = client.GetOriginalURL(ctx, &mygrpc.GetOriginalURLRequest{ShortUrl: resp.ShortUrl})
+	if st, _ := status.FromError(err); st.Code() != codes.NotFound {
+		t.Errorf("Expected code to be %s, got %s", codes.NotFound, st.Code())
+	}
+
+	again, err := client.CreateShortURL(ctx, &mygrpc.CreateShortURLRequest{OriginalUrl: "https://example.com"})
+	if err != nil {
+		t.Fatalf("CreateShortURL failed: %v", err)
+	}
+	if again.ShortUrl == resp.ShortUrl || again.ExpiresAt != nil {
+		t.Errorf("Expected a new permanent short url, got %v", again)
+	}
+}
+
+func TestGetURLStats_InMemory(t *testing.T) {
+	cfg := config.MustLoad()
+
+	memStorage := memory.New()
+	recorder := analytics.NewRecorder(memStorage, 100, 10, time.Hour)
+	defer recorder.Close()
+	testService := service.NewURLShortenerService(memStorage, cfg.ShortURLLength, service.WithClickRecorder(recorder))
+
+	s := grpc.NewServer()
+	mygrpc.RegisterURLShortenerServer(s, mygrpc.NewURLShortenerServer(testService))
+	lis, errChan := newBufConnListener(t, s)
+	client, close := newTestClient(t, lis)
+	defer close()
+	defer s.GracefulStop()
+	select {
+	case err := <-errChan:
+		t.Fatalf("gRPC server failed: %v", err)
+	default:
+	}
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "referer", "https://news.example.com")
+	if _, err := client.CreateShortURL(ctx, &mygrpc.CreateShortURLRequest{OriginalUrl: "https://example.com", CustomAlias: "clicked"}); err != nil {
+		t.Fatalf("CreateShortURL failed: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := client.GetOriginalURL(ctx, &mygrpc.GetOriginalURLRequest{ShortUrl: "clicked"}); err != nil {
+			t.Fatalf("GetOriginalURL failed: %v", err)
+		}
+	}
+	recorder.Flush()
+
+	resp, err := client.GetURLStats(ctx, &mygrpc.GetURLStatsRequest{ShortUrl: "clicked", Days: 7})
+	if err != nil {
+		t.Fatalf("GetURLStats failed: %v", err)
+	}
+	if resp.TotalClicks != 3 {
+		t.Errorf("Expected 3 clicks, got %d", resp.TotalClicks)
+	}
+	if len(resp.DailyClicks) != 7 {
+		t.Fatalf("Expected 7 days in histogram, got %d", len(resp.DailyClicks))
+	}
+	today := resp.DailyClicks[len(resp.DailyClicks)-1]
+	if today.Date != time.Now().UTC().Format(time.DateOnly) || today.Clicks != 3 {
+		t.Errorf("Expected 3 clicks today, got %v", today)
+	}
+
+	_, err = client.GetURLStats(ctx, &mygrpc.GetURLStatsRequest{ShortUrl: "missing"})
+	if st, _ := status.FromError(err); st.Code() != codes.NotFound {
+		t.Errorf("Expected code to be %s, got %s", codes.NotFound, st.Code())
+	}
+
+	// The histogram has an entry per day, so the window is bounded.
+	for _, days := range []int32{-1, 367, math.MaxInt32} {
+		_, err = client.GetURLStats(ctx, &mygrpc.GetURLStatsRequest{ShortUrl: "clicked", Days: days})
+		if st, _ := status.FromError(err); st.Code() != codes.InvalidArgument {
+			t.Errorf("days %d: expected code to be %s, got %s", days, codes.InvalidArgument, st.Code())
+		}
+	}
+	if resp, err := client.GetURLStats(ctx, &mygrpc.GetURLStatsRequest{ShortUrl: "clicked", Days: 366}); err != nil || len(resp.DailyClicks) != 366 {
+		t.Errorf("Expected 366 days in histogram, got %v", err)
+	}
+
+	// A reused alias starts without the clicks of the deleted link, including
+	// the ones still buffered when it was deleted.
+	if _, err := client.GetOriginalURL(ctx, &mygrpc.GetOriginalURLRequest{ShortUrl: "clicked"}); err != nil {
+		t.Fatalf("GetOriginalURL failed: %v", err)
+	}
+	if err := memStorage.DeleteURL(ctx, "clicked", false); err != nil {
+		t.Fatalf("DeleteURL failed: %v", err)
+	}
+	if _, err := memStorage.SaveURL(ctx, storage.URL{ShortURL: "clicked", OriginalURL: "https://example.org"}); err != nil {
+		t.Fatalf("Failed to save url to memory storage %v", err)
+	}
+	recorder.Flush()
+	resp, err = client.GetURLStats(ctx, &mygrpc.GetURLStatsRequest{ShortUrl: "clicked", Days: 7})
+	if err != nil {
+		t.Fatalf("GetURLStats failed: %v", err)
+	}
+	if resp.TotalClicks != 0 {
+		t.Errorf("Expected the reused alias to have no clicks, got %d", resp.TotalClicks)
+	}
+
+	// Clicks of requests that outlive the recorder are dropped.
+	recorder.Close()
+	recorder.Record(storage.Click{ShortURL: "clicked", ClickedAt: time.Now()})
+}
+
+func TestCreateShortURLs_InMemory(t *testing.T) {
+	cfg := config.MustLoad()
+
+	memStorage := memory.New()
+
+	s := newTestGRPCServer(t, memStorage, *cfg)
+	lis, errChan := newBufConnListener(t, s)
+	client, close := newTestClient(t, lis)
+	defer close()
+	defer s.GracefulStop()
+	select {
+	case err := <-errChan:
+		t.Fatalf("gRPC server failed: %v", err)
+	default:
+	}
+
+	if _, err := memStorage.SaveURL(context.Background(), storage.URL{ShortURL: "taken", OriginalURL: "https://example.com/existing"}); err != nil {
+		t.Fatalf("Failed to save url to memory storage %v", err)
+	}
+
+	resp, err := client.CreateShortURLs(context.Background(), &mygrpc.CreateShortURLsRequest{
+		Items: []*mygrpc.CreateShortURLRequest{
+			{OriginalUrl: "https://example.com/1"},
+			{OriginalUrl: "https://example.com/2", CustomAlias: "second"},
+			{OriginalUrl: "https://example.com/existing"},
+			{OriginalUrl: "https://example.com/3", CustomAlias: "taken"},
+			{OriginalUrl: ""},
+			{OriginalUrl: "https://example.com/1"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateShortURLs failed: %v", err)
+	}
+	if len(resp.Results) != 6 {
+		t.Fatalf("Expected 6 results, got %d", len(resp.Results))
+	}
+
+	expectedCodes := []codes.Code{codes.OK, codes.OK, codes.OK, codes.AlreadyExists, codes.InvalidArgument, codes.OK}
+	for i, result := range resp.Results {
+		if codes.Code(result.Code) != expectedCodes[i] {
+			t.Errorf("item %d: expected code %s, got %s (%s)", i, expectedCodes[i], codes.Code(result.Code), result.Message)
+		}
+	}
+
+	if resp.Results[1].ShortUrl != "second" {
+		t.Errorf("Expected custom alias to be used, got %s", resp.Results[1].ShortUrl)
+	}
+	if resp.Results[2].ShortUrl != "taken" {
+		t.Errorf("Expected existing short url to be returned, got %s", resp.Results[2].ShortUrl)
+	}
+	if resp.Results[0].ShortUrl == "" || resp.Results[5].ShortUrl != resp.Results[0].ShortUrl {
+		t.Errorf("Expected duplicate url to share the short url, got %s and %s", resp.Results[0].ShortUrl, resp.Results[5].ShortUrl)
+	}
+
+	url, err := memStorage.GetURL(context.Background(), resp.Results[0].ShortUrl)
+	if err != nil || url != "https://example.com/1" {
+		t.Errorf("URL in storage is not the same as original URL: %s, %v", url, err)
+	}
+}
+
+func TestCreateShortURLs_Postgres(t *testing.T) {
+	cfg := config.MustLoad()
+	pgStorage := newTestPostgresStorage(t, *cfg)
+	defer func() {
+		if err := pgStorage.Close(); err != nil {
+			t.Fatalf("failed to close database connection: %v", err)
+		}
+	}()
+
+	s := newTestGRPCServer(t, pgStorage, *cfg)
+	lis, errChan := newBufConnListener(t, s)
+	client, close := newTestClient(t, lis)
+	defer close()
+	defer s.GracefulStop()
+	select {
+	case err := <-errChan:
+		t.Fatalf("gRPC server failed: %v", err)
+	default:
+	}
+
+	if _, err := pgStorage.SaveURL(context.Background(), storage.URL{ShortURL: "taken", OriginalURL: "https://example.com/existing"}); err != nil {
+		t.Fatalf("Failed to save url to database %v", err)
+	}
+
+	resp, err := client.CreateShortURLs(context.Background(), &mygrpc.CreateShortURLsRequest{
+		Items: []*mygrpc.CreateShortURLRequest{
+			{OriginalUrl: "https://example.com/1"},
+			{OriginalUrl: "https://example.com/2", CustomAlias: "second"},
+			{OriginalUrl: "https://example.com/existing"},
+			{OriginalUrl: "https://example.com/3", CustomAlias: "taken"},
+			{OriginalUrl: ""},
+			{OriginalUrl: "https://example.com/1"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateShortURLs failed: %v", err)
+	}
+	if len(resp.Results) != 6 {
+		t.Fatalf("Expected 6 results, got %d", len(resp.Results))
+	}
+
+	expectedCodes := []codes.Code{codes.OK, codes.OK, codes.OK, codes.AlreadyExists, codes.InvalidArgument, codes.OK}
+	for i, result := range resp.Results {
+		if codes.Code(result.Code) != expectedCodes[i] {
+			t.Errorf("item %d: expected code %s, got %s (%s)", i, expectedCodes[i], codes.Code(result.Code), result.Message)
+		}
+	}
+
+	if resp.Results[1].ShortUrl != "second" {
+		t.Errorf("Expected custom alias to be used, got %s", resp.Results[1].ShortUrl)
+	}
+	if resp.Results[2].ShortUrl != "taken" {
+		t.Errorf("Expected existing short url to be returned, got %s", resp.Results[2].ShortUrl)
+	}
+	if resp.Results[0].ShortUrl == "" || resp.Results[5].ShortUrl != resp.Results[0].ShortUrl {
+		t.Errorf("Expected duplicate url to share the short url, got %s and %s", resp.Results[0].ShortUrl, resp.Results[5].ShortUrl)
+	}
+
+	url, err := pgStorage.GetURL(context.Background(), resp.Results[0].ShortUrl)
+	if err != nil || url != "https://example.com/1" {
+		t.Errorf("URL in storage is not the same as original URL: %s, %v", url, err)
+	}
+}
+
+func TestSaveURLs_Postgres(t *testing.T) {
+	cfg := config.MustLoad()
+	ctx := context.Background()
+	pgStorage := newTestPostgresStorage(t, *cfg)
+	defer func() {
+		if err := pgStorage.Close(); err != nil {
+			t.Fatalf("failed to close database connection: %v", err)
+		}
+	}()
+
+	if _, err := pgStorage.SaveURL(ctx, storage.URL{ShortURL: "taken", OriginalURL: "https://example.com/existing"}); err != nil {
+		t.Fatalf("Failed to save url to database %v", err)
+	}
+	if _, err := pgStorage.SaveURL(ctx, storage.URL{ShortURL: "retired", OriginalURL: "https://example.com/retired"}); err != nil {
+		t.Fatalf("Failed to save url to database %v", err)
+	}
+	if err := pgStorage.DeleteURL(ctx, "retired", true); err != nil {
+		t.Fatalf("Failed to delete url: %v", err)
+	}
+
+	createdAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	errs, err := pgStorage.SaveURLs(ctx, []storage.URL{
+		{ShortURL: "first", OriginalURL: "https://example.com/1", CreatedAt: createdAt},
+		{ShortURL: "second", OriginalURL: "https://example.com/existing"},
+		{ShortURL: "taken", OriginalURL: "https://example.com/2"},
+		{ShortURL: "retired", OriginalURL: "https://example.com/3"},
+		{ShortURL: "third", OriginalURL: "https://example.com/1"},
+		{ShortURL: "fourth", OriginalURL: "https://example.com/4", ExpiresAt: time.Now().Add(time.Hour)},
+	})
+	if err != nil {
+		t.Fatalf("SaveURLs failed: %v", err)
+	}
+
+	expected := []error{nil, storage.ErrOriginalURLExists, storage.ErrAliasExists, storage.ErrAliasExists, storage.ErrOriginalURLExists, nil}
+	for i, err := range errs {
+		if !errors.Is(err, expected[i]) {
+			t.Errorf("item %d: expected %v, got %v", i, expected[i], err)
+		}
+	}
+
+	var stored time.Time
+	if err := pgStorage.Db.QueryRow("SELECT created_at FROM urls WHERE short_url = 'first'").Scan(&stored); err != nil || !stored.Equal(createdAt) {
+		t.Errorf("Expected created_at %v to be kept, got %v, %v", createdAt, stored, err)
+	}
+	if url, err := pgStorage.GetURL(ctx, "fourth"); err != nil || url != "https://example.com/4" {
+		t.Errorf("Expected fourth to point to https://example.com/4, got %q, %v", url, err)
+	}
+}
+
+func TestImportURLs_InMemory(t *testing.T) {
+	cfg := config.MustLoad()
+
+	memStorage := memory.New()
+
+	s := newTestGRPCServer(t, memStorage, *cfg)
+	lis, errChan := newBufConnListener(t, s)
+	client, close := newTestClient(t, lis)
+	defer close()
+	defer s.GracefulStop()
+	select {
+	case err := <-errChan:
+		t.Fatalf("gRPC server failed: %v", err)
+	default:
+	}
+
+	if _, err := memStorage.SaveURL(context.Background(), storage.URL{ShortURL: "existing", OriginalURL: "https://example.com/existing"}); err != nil {
+		t.Fatalf("Failed to save url to memory storage %v", err)
+	}
+
+	stream, err := client.ImportURLs(context.Background())
+	if err != nil {
+		t.Fatalf("ImportURLs failed: %v", err)
+	}
+	createdAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	rows := []*mygrpc.ImportURLsRequest{
+		{ShortUrl: "first", OriginalUrl: "https://example.com/1", CreatedAt: timestamppb.New(createdAt)},
+		{ShortUrl: "existing", OriginalUrl: "https://example.com/existing"},
+		{ShortUrl: "existing", OriginalUrl: "https://example.com/2"},
+		{ShortUrl: "third", OriginalUrl: "https://example.com/existing"},
+		{ShortUrl: "", OriginalUrl: "https://example.com/4"},
+		{ShortUrl: "fifth", OriginalUrl: "https://example.com/5"},
+	}
+	for _, row := range rows {
+		if err := stream.Send(row); err != nil {
+			t.Fatalf("Send failed: %v", err)
+		}
+	}
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		t.Fatalf("CloseAndRecv failed: %v", err)
+	}
+
+	if resp.Imported != 3 || resp.ConflictCount != 3 {
+		t.Errorf("Expected 3 imported and 3 conflicts, got %d and %d", resp.Imported, resp.ConflictCount)
+	}
+
+	expected := map[int64]codes.Code{2: codes.AlreadyExists, 3: codes.AlreadyExists, 4: codes.InvalidArgument}
+	for _, c := range resp.Conflicts {
+		if code, ok := expected[c.Index]; !ok || codes.Code(c.Code) != code {
+			t.Errorf("unexpected conflict %v", c)
+		}
+	}
+
+	for alias, want := range map[string]string{"first": "https://example.com/1", "fifth": "https://example.com/5"} {
+		url, err := memStorage.GetURL(context.Background(), alias)
+		if err != nil || url != want {
+			t.Errorf("Expected %s to be imported as %s, got %s, %v", alias, want, url, err)
+		}
+	}
+
+	// The creation time of the import source is kept.
+	var first storage.URL
+	memStorage.ForEachURL(context.Background(), func(url storage.URL) error {
+		if url.ShortURL == "first" {
+			first = url
+		}
+		return nil
+	})
+	if !first.CreatedAt.Equal(createdAt) {
+		t.Errorf("Expected first to keep created_at %v, got %v", createdAt, first.CreatedAt)
+	}
+}
+
+func TestExportURLs_InMemory(t *testing.T) {
+	cfg := config.MustLoad()
+
+	memStorage := memory.New()
+
+	s := newTestGRPCServer(t, memStorage, *cfg)
+	lis, errChan := newBufConnListener(t, s)
+	client, close := newTestClient(t, lis)
+	defer close()
+	defer s.GracefulStop()
+	select {
+	case err := <-errChan:
+		t.Fatalf("gRPC server failed: %v", err)
+	default:
+	}
+
+	expiresAt := time.Now().Add(time.Hour)
+	if _, err := memStorage.SaveURL(context.Background(), storage.URL{ShortURL: "first", OriginalURL: "https://example.com/1"}); err != nil {
+		t.Fatalf("Failed to save url to memory storage %v", err)
+	}
+	if _, err := memStorage.SaveURL(context.Background(), storage.URL{ShortURL: "second", OriginalURL: "https://example.com/2", ExpiresAt: expiresAt}); err != nil {
+		t.Fatalf("Failed to save url to memory storage %v", err)
+	}
+
+	stream, err := client.ExportURLs(context.Background(), &mygrpc.ExportURLsRequest{})
+	if err != nil {
+		t.Fatalf("ExportURLs failed: %v", err)
+	}
+
+	var urls []*mygrpc.URL
+	for {
+		url, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv failed: %v", err)
+		}
+		urls = append(urls, url)
+	}
+
+	if len(urls) != 2 {
+		t.Fatalf("Expected 2 urls, got %d", len(urls))
+	}
+	if urls[0].ShortUrl != "first" || urls[0].ExpiresAt != nil || urls[0].CreatedAt == nil {
+		t.Errorf("unexpected first url %v", urls[0])
+	}
+	if urls[1].ShortUrl != "second" || !urls[1].ExpiresAt.AsTime().Equal(expiresAt) {
+		t.Errorf("unexpected second url %v", urls[1])
+	}
+}
+
+func TestListURLs_InMemory(t *testing.T) {
+	cfg := config.MustLoad()
+
+	memStorage := memory.New()
+
+	s := newTestGRPCServer(t, memStorage, *cfg)
+	lis, errChan := newBufConnListener(t, s)
+	client, close := newTestClient(t, lis)
+	defer close()
+	defer s.GracefulStop()
+	select {
+	case err := <-errChan:
+		t.Fatalf("gRPC server failed: %v", err)
+	default:
+	}
+
+	var aliases []string
+	for i := 0; i < 7; i++ {
+		alias := fmt.Sprintf("promo%d", i)
+		if i%2 == 1 {
+			alias = fmt.Sprintf("other%d", i)
+		}
+		if _, err := memStorage.SaveURL(context.Background(), storage.URL{ShortURL: alias, OriginalURL: fmt.Sprintf("https://example.com/%d", i)}); err != nil {
+			t.Fatalf("Failed to save url to memory storage %v", err)
+		}
+		aliases = append(aliases, alias)
+	}
+	if err := memStorage.DeleteURL(context.Background(), "promo2", true); err != nil {
+		t.Fatalf("Failed to delete url: %v", err)
+	}
+	aliases = append(aliases[:2], aliases[3:]...)
+
+	listAll := func(req *mygrpc.ListURLsRequest) []string {
+		var got []string
+		for {
+			resp, err := client.ListURLs(context.Background(), req)
+			if err != nil {
+				t.Fatalf("ListURLs failed: %v", err)
+			}
+			if len(resp.Urls) > int(req.PageSize) {
+				t.Fatalf("Expected at most %d urls, got %d", req.PageSize, len(resp.Urls))
+			}
+			for _, url := range resp.Urls {
+				got = append(got, url.ShortUrl)
+			}
+			if resp.NextPageToken == "" {
+				return got
+			}
+			req.PageToken = resp.NextPageToken
+		}
+	}
+
+	if got := listAll(&mygrpc.ListURLsRequest{PageSize: 2}); fmt.Sprint(got) != fmt.Sprint(aliases) {
+		t.Errorf("Expected %v, got %v", aliases, got)
+	}
+
+	var reversed []string
+	for i := len(aliases) - 1; i >= 0; i-- {
+		reversed = append(reversed, aliases[i])
+	}
+	if got := listAll(&mygrpc.ListURLsRequest{PageSize: 4, Descending: true}); fmt.Sprint(got) != fmt.Sprint(reversed) {
+		t.Errorf("Expected %v, got %v", reversed, got)
+	}
+
+	if got := listAll(&mygrpc.ListURLsRequest{PageSize: 1, AliasPrefix: "promo"}); fmt.Sprint(got) != "[promo0 promo4 promo6]" {
+		t.Errorf("Expected [promo0 promo4 promo6], got %v", got)
+	}
+	if got := listAll(&mygrpc.ListURLsRequest{PageSize: 10, OriginalUrlContains: "com/3"}); fmt.Sprint(got) != "[other3]" {
+		t.Errorf("Expected [other3], got %v", got)
+	}
+
+	_, err := client.ListURLs(context.Background(), &mygrpc.ListURLsRequest{PageToken: "garbage"})
+	if st, _ := status.FromError(err); st.Code() != codes.InvalidArgument {
+		t.Errorf("Expected code to be %s, got %s", codes.InvalidArgument, st.Code())
+	}
+}
+
+func TestListURLs_Postgres(t *testing.T) {
+	cfg := config.MustLoad()
+	pgStorage := newTestPostgresStorage(t, *cfg)
+	defer func() {
+		if err := pgStorage.Close(); err != nil {
+			t.Fatalf("failed to close database connection: %v", err)
+		}
+	}()
+
+	s := newTestGRPCServer(t, pgStorage, *cfg)
+	lis, errChan := newBufConnListener(t, s)
+	client, close := newTestClient(t, lis)
+	defer close()
+	defer s.GracefulStop()
+	select {
+	case err := <-errChan:
+		t.Fatalf("gRPC server failed: %v", err)
+	default:
+	}
+
+	// All URLs share the creation time, so that pages are split on the alias
+	// as well.
+	createdAt := time.Now().Add(-time.Hour).Truncate(time.Microsecond)
+	var urls []storage.URL
+	for i := 0; i < 7; i++ {
+		alias := fmt.Sprintf("promo%d", i)
+		if i%2 == 1 {
+			alias = fmt.Sprintf("other%d", i)
+		}
+		urls = append(urls, storage.URL{ShortURL: alias, OriginalURL: fmt.Sprintf("https://example.com/%d", i), CreatedAt: createdAt})
+	}
+	if _, err := pgStorage.SaveURLs(context.Background(), urls); err != nil {
+		t.Fatalf("Failed to save urls to database %v", err)
+	}
+	if err := pgStorage.DeleteURL(context.Background(), "promo2", true); err != nil {
+		t.Fatalf("Failed to delete url: %v", err)
+	}
+	aliases := []string{"other1", "other3", "other5", "promo0", "promo4", "promo6"}
+
+	listAll := func(req *mygrpc.ListURLsRequest) []string {
+		var got []string
+		for {
+			resp, err := client.ListURLs(context.Background(), req)
+			if err != nil {
+				t.Fatalf("ListURLs failed: %v", err)
+			}
+			if len(resp.Urls) > int(req.PageSize) {
+				t.Fatalf("Expected at most %d urls, got %d", req.PageSize, len(resp.Urls))
+			}
+			for _, url := range resp.Urls {
+				got = append(got, url.ShortUrl)
+			}
+			if resp.NextPageToken == "" {
+				return got
+			}
+			req.PageToken = resp.NextPageToken
+		}
+	}
+
+	if got := listAll(&mygrpc.ListURLsRequest{PageSize: 2}); fmt.Sprint(got) != fmt.Sprint(aliases) {
+		t.Errorf("Expected %v, got %v", aliases, got)
+	}
+
+	var reversed []string
+	for i := len(aliases) - 1; i >= 0; i-- {
+		reversed = append(reversed, aliases[i])
+	}
+	if got := listAll(&mygrpc.ListURLsRequest{PageSize: 4, Descending: true}); fmt.Sprint(got) != fmt.Sprint(reversed) {
+		t.Errorf("Expected %v, got %v", reversed, got)
+	}
+
+	// A newer URL comes after all of them.
+	if _, err := pgStorage.SaveURL(context.Background(), storage.URL{ShortURL: "newest", OriginalURL: "https://example.com/newest"}); err != nil {
+		t.Fatalf("Failed to save url to database %v", err)
+	}
+	if got := listAll(&mygrpc.ListURLsRequest{PageSize: 3}); fmt.Sprint(got) != fmt.Sprint(append(aliases, "newest")) {
+		t.Errorf("Expected %v, got %v", append(aliases, "newest"), got)
+	}
+
+	if got := listAll(&mygrpc.ListURLsRequest{PageSize: 1, AliasPrefix: "promo"}); fmt.Sprint(got) != "[promo0 promo4 promo6]" {
+		t.Errorf("Expected [promo0 promo4 promo6], got %v", got)
+	}
+	if got := listAll(&mygrpc.ListURLsRequest{PageSize: 10, OriginalUrlContains: "com/3"}); fmt.Sprint(got) != "[other3]" {
+		t.Errorf("Expected [other3], got %v", got)
+	}
+	// LIKE wildcards in the filters are matched literally.
+	if got := listAll(&mygrpc.ListURLsRequest{PageSize: 10, AliasPrefix: "_"}); len(got) != 0 {
+		t.Errorf("Expected no urls, got %v", got)
+	}
+}
+
+// slowStorage blocks lookups until the caller's context is done.
+type slowStorage struct {
+	*memory.MemoryStorage
+}
+
+func (s slowStorage) GetURL(ctx context.Context, alias string) (string, error) {
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func TestStorageDeadline_InMemory(t *testing.T) {
+	cfg := config.MustLoad()
+
+	memStorage := memory.New()
+	if _, err := memStorage.SaveURL(context.Background(), storage.URL{ShortURL: "promo", OriginalURL: "https://example.com"}); err != nil {
+		t.Fatalf("Failed to save url to memory storage %v", err)
+	}
+	testService := newTestService(t, slowStorage{memStorage}, *cfg)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := testService.GetOriginalURL(ctx, "promo"); !errors.Is(err, service.ErrDeadlineExceeded) {
+		t.Errorf("Expected ErrDeadlineExceeded, got %v", err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if _, err := testService.GetOriginalURL(ctx, "promo"); !errors.Is(err, service.ErrCanceled) {
+		t.Errorf("Expected ErrCanceled, got %v", err)
+	}
+}
+
+func TestCreateShortURL_Concurrent_InMemory(t *testing.T) {
+	cfg := config.MustLoad()
+
+	memStorage := memory.New()
+	testService := newTestService(t, memStorage, *cfg)
+
+	const workers = 16
+	aliases := make(chan string, workers)
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			url, err := testService.CreateShortURL(context.Background(), "https://example.com/concurrent", "", time.Time{}, service.DedupeReuse)
+			if err != nil {
+				errs <- err
+				return
+			}
+			aliases <- url.ShortURL
+		}()
+	}
+
+	var first string
+	for i := 0; i < workers; i++ {
+		select {
+		case err := <-errs:
+			t.Fatalf("CreateShortURL failed: %v", err)
+		case alias := <-aliases:
+			if first == "" {
+				first = alias
+			}
+			if alias != first {
+				t.Errorf("Expected every call to return %q, got %q", first, alias)
+			}
+		}
+	}
+}
+
+func TestCreateShortURL_Concurrent_Postgres(t *testing.T) {
+	cfg := config.MustLoad()
+	pgStorage := newTestPostgresStorage(t, *cfg)
+	defer func() {
+		if err := pgStorage.Close(); err != nil {
+			t.Fatalf("failed to close database connection: %v", err)
+		}
+	}()
+	testService := newTestService(t, pgStorage, *cfg)
+
+	const workers = 16
+	aliases := make(chan string, workers)
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			url, err := testService.CreateShortURL(context.Background(), "https://example.com/concurrent", "", time.Time{}, service.DedupeReuse)
+			if err != nil {
+				errs <- err
+				return
+			}
+			aliases <- url.ShortURL
+		}()
+	}
+
+	var first string
+	for i := 0; i < workers; i++ {
+		select {
+		case err := <-errs:
+			t.Fatalf("CreateShortURL failed: %v", err)
+		case alias := <-aliases:
+			if first == "" {
+				first = alias
+			}
+			if alias != first {
+				t.Errorf("Expected every call to return %q, got %q", first, alias)
+			}
+		}
+	}
+
+	var count int
+	if err := pgStorage.Db.QueryRow("SELECT count(*) FROM urls").Scan(&count); err != nil || count != 1 {
+		t.Errorf("Expected a single stored url, got %d, %v", count, err)
+	}
+}
+
+func TestSaveURLConflicts_InMemory(t *testing.T) {
+	ctx := context.Background()
+	memStorage := memory.New()
+
+	saved, err := memStorage.SaveURL(ctx, storage.URL{ShortURL: "promo", OriginalURL: "https://example.com"})
+	if err != nil {
+		t.Fatalf("Failed to save url to memory storage %v", err)
+	}
+
+	existing, err := memStorage.SaveURL(ctx, storage.URL{ShortURL: "other", OriginalURL: "https://example.com"})
+	if !errors.Is(err, storage.ErrOriginalURLExists) {
+		t.Errorf("Expected ErrOriginalURLExists, got %v", err)
+	}
+	if existing != saved {
+		t.Errorf("Expected existing mapping %+v, got %+v", saved, existing)
+	}
+
+	if _, err := memStorage.SaveURL(ctx, storage.URL{ShortURL: "promo", OriginalURL: "https://example.net"}); !errors.Is(err, storage.ErrAliasExists) {
+		t.Errorf("Expected ErrAliasExists, got %v", err)
+	}
+
+	if err := memStorage.DeleteURL(ctx, "promo", true); err != nil {
+		t.Fatalf("Failed to delete url: %v", err)
+	}
+	if _, err := memStorage.SaveURL(ctx, storage.URL{ShortURL: "promo", OriginalURL: "https://example.net"}); !errors.Is(err, storage.ErrAliasExists) {
+		t.Errorf("Expected ErrAliasExists for a retired alias, got %v", err)
+	}
+}
+
+func TestSaveURLConflicts_Postgres(t *testing.T) {
+	cfg := config.MustLoad()
+	ctx := context.Background()
+	pgStorage := newTestPostgresStorage(t, *cfg)
+	defer func() {
+		if err := pgStorage.Close(); err != nil {
+			t.Fatalf("failed to close database connection: %v", err)
+		}
+	}()
+
+	saved, err := pgStorage.SaveURL(ctx, storage.URL{ShortURL: "promo", OriginalURL: "https://example.com"})
+	if err != nil {
+		t.Fatalf("Failed to save url to database %v", err)
+	}
+
+	existing, err := pgStorage.SaveURL(ctx, storage.URL{ShortURL: "other", OriginalURL: "https://example.com"})
+	if !errors.Is(err, storage.ErrOriginalURLExists) {
+		t.Errorf("Expected ErrOriginalURLExists, got %v", err)
+	}
+	if existing.ShortURL != saved.ShortURL || existing.OriginalURL != saved.OriginalURL || !existing.CreatedAt.Equal(saved.CreatedAt) {
+		t.Errorf("Expected existing mapping %+v, got %+v", saved, existing)
+	}
+
+	// The canonical URL conflict is reported when the alias is taken too.
+	if _, err := pgStorage.SaveURL(ctx, storage.URL{ShortURL: "promo", OriginalURL: "https://example.com"}); !errors.Is(err, storage.ErrOriginalURLExists) {
+		t.Errorf("Expected ErrOriginalURLExists, got %v", err)
+	}
+
+	if _, err := pgStorage.SaveURL(ctx, storage.URL{ShortURL: "promo", OriginalURL: "https://example.net"}); !errors.Is(err, storage.ErrAliasExists) {
+		t.Errorf("Expected ErrAliasExists, got %v", err)
+	}
+
+	// Clicks are deleted along with their alias.
+	if err := pgStorage.SaveClicks(ctx, []storage.Click{{ShortURL: "promo", ClickedAt: time.Now()}}); err != nil {
+		t.Fatalf("Failed to save clicks: %v", err)
+	}
+	if stats, err := pgStorage.GetClickStats(ctx, "promo", time.Time{}); err != nil || stats.Total != 1 {
+		t.Errorf("Expected the click to be saved, got %+v, %v", stats, err)
+	}
+	if err := pgStorage.DeleteURL(ctx, "promo", true); err != nil {
+		t.Fatalf("Failed to delete url: %v", err)
+	}
+	if stats, err := pgStorage.GetClickStats(ctx, "promo", time.Time{}); err != nil || stats.Total != 0 {
+		t.Errorf("Expected the clicks to be deleted, got %+v, %v", stats, err)
+	}
+	// Clicks flushed after their alias was deleted are skipped.
+	if err := pgStorage.SaveClicks(ctx, []storage.Click{{ShortURL: "promo", ClickedAt: time.Now()}}); err != nil {
+		t.Fatalf("Failed to save clicks: %v", err)
+	}
+	if stats, err := pgStorage.GetClickStats(ctx, "promo", time.Time{}); err != nil || stats.Total != 0 {
+		t.Errorf("Expected the clicks of a deleted alias to be skipped, got %+v, %v", stats, err)
+	}
+	if _, err := pgStorage.SaveURL(ctx, storage.URL{ShortURL: "promo", OriginalURL: "https://example.net"}); !errors.Is(err, storage.ErrAliasExists) {
+		t.Errorf("Expected ErrAliasExists for a retired alias, got %v", err)
+	}
+	if _, err := pgStorage.SaveAdditionalURL(ctx, storage.URL{ShortURL: "promo", OriginalURL: "https://example.net"}); !errors.Is(err, storage.ErrAliasExists) {
+		t.Errorf("Expected ErrAliasExists for a retired alias, got %v", err)
+	}
+
+	// The original URL is released with its only alias.
+	if _, err := pgStorage.SaveURL(ctx, storage.URL{ShortURL: "again", OriginalURL: "https://example.com"}); err != nil {
+		t.Errorf("Expected the released url to be saved, got %v", err)
+	}
+}
+
+// collidingStorage rejects every alias shorter than minLength as taken and
+// fails every insert if err is set.
+type collidingStorage struct {
+	*memory.MemoryStorage
+	minLength int
+	err       error
+}
+
+func (s collidingStorage) SaveURL(ctx context.Context, url storage.URL) (storage.URL, error) {
+	if s.err != nil {
+		return storage.URL{}, s.err
+	}
+	if len(url.ShortURL) < s.minLength {
+		return storage.URL{}, storage.ErrAliasExists
+	}
+	return s.MemoryStorage.SaveURL(ctx, url)
+}
+
+func TestCreateShortURL_KeyspaceExhausted_InMemory(t *testing.T) {
+	cfg := config.MustLoad()
+
+	tests := []struct {
+		name    string
+		storage collidingStorage
+		code    codes.Code
+	}{
+		{
+			name:    "collisions",
+			storage: collidingStorage{MemoryStorage: memory.New(), minLength: cfg.ShortURLLength + 1},
+			code:    codes.ResourceExhausted,
+		},
+		{
+			name:    "storage failures",
+			storage: collidingStorage{MemoryStorage: memory.New(), err: errors.New("connection refused")},
+			code:    codes.Unavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestGRPCServer(t, tt.storage, *cfg)
+			lis, _ := newBufConnListener(t, s)
+			client, close := newTestClient(t, lis)
+			defer close()
+			defer s.GracefulStop()
+
+			_, err := client.CreateShortURL(context.Background(), &mygrpc.CreateShortURLRequest{OriginalUrl: "https://example.com"})
+			if status.Code(err) != tt.code {
+				t.Errorf("Expected %s, got %v", tt.code, err)
+			}
+		})
+	}
+}
+
+func TestCreateShortURL_AutoGrow_InMemory(t *testing.T) {
+	cfg := config.MustLoad()
+
+	colliding := collidingStorage{MemoryStorage: memory.New(), minLength: cfg.ShortURLLength + 2}
+	testService := service.NewURLShortenerService(colliding, cfg.ShortURLLength,
+		service.WithMaxAttempts(100),
+		service.WithAutoGrow(0.1, cfg.ShortURLLength+2),
+	)
+
+	url, err := testService.CreateShortURL(context.Background(), "https://example.com", "", time.Time{}, service.DedupeReuse)
+	if err != nil {
+		t.Fatalf("CreateShortURL failed: %v", err)
+	}
+	if len(url.ShortURL) != cfg.ShortURLLength+2 {
+		t.Errorf("Expected alias of %d characters, got %q", cfg.ShortURLLength+2, url.ShortURL)
+	}
+}
+
+func TestCreateShortURL_CounterAliases_InMemory(t *testing.T) {
+	cfg := config.MustLoad()
+	ctx := context.Background()
+
+	memStorage := memory.New()
+	testService := service.NewURLShortenerService(memStorage, cfg.ShortURLLength,
+		service.WithAliasGenerator(alias.NewCounter(alias.MustParseAlphabet("base62"), memStorage)),
+	)
+
+	if _, err := testService.CreateShortURL(ctx, "https://example.com/custom", "2", time.Time{}, service.DedupeReuse); err != nil {
+		t.Fatalf("CreateShortURL failed: %v", err)
+	}
+
+	var got []string
+	for i := 0; i < 3; i++ {
+		url, err := testService.CreateShortURL(ctx, fmt.Sprintf("https://example.com/%d", i), "", time.Time{}, service.DedupeReuse)
+		if err != nil {
+			t.Fatalf("CreateShortURL failed: %v", err)
+		}
+		got = append(got, url.ShortURL)
+	}
+
+	// The taken alias "2" is skipped.
+	if fmt.Sprint(got) != "[1 3 4]" {
+		t.Errorf("Expected aliases [1 3 4], got %v", got)
+	}
+}
+
+func TestCheckCharacter_InMemory(t *testing.T) {
+	cfg := config.MustLoad()
+	ctx := context.Background()
+
+	alphabet := alias.MustParseAlphabet("human-safe")
+	memStorage := memory.New()
+	testService := service.NewURLShortenerService(memStorage, cfg.ShortURLLength,
+		service.WithAliasGenerator(alias.NewRandom(alphabet)),
+		service.WithCheckCharacter(alphabet),
+	)
+
+	url, err := testService.CreateShortURL(ctx, "https://example.com", "", time.Time{}, service.DedupeReuse)
+	if err != nil {
+		t.Fatalf("CreateShortURL failed: %v", err)
+	}
+	if len(url.ShortURL) != cfg.ShortURLLength+1 || !alphabet.Valid(url.ShortURL) {
+		t.Fatalf("Expected a valid alias of %d characters, got %q", cfg.ShortURLLength+1, url.ShortURL)
+	}
+	if _, err := testService.GetOriginalURL(ctx, url.ShortURL); err != nil {
+		t.Errorf("GetOriginalURL failed: %v", err)
+	}
+
+	// Replace the check character by another one.
+	other := "2"
+	if strings.HasSuffix(url.ShortURL, other) {
+		other = "3"
+	}
+	typo := url.ShortURL[:len(url.ShortURL)-1] + other
+	if _, err := testService.GetOriginalURL(ctx, typo); !errors.Is(err, service.ErrInvalidArgument) {
+		t.Errorf("Expected ErrInvalidArgument for %q, got %v", typo, err)
+	}
+
+	if _, err := testService.CreateShortURL(ctx, "https://example.net", "prize", time.Time{}, service.DedupeReuse); !errors.Is(err, service.ErrInvalidArgument) {
+		t.Errorf("Expected ErrInvalidArgument for a custom alias without check character, got %v", err)
+	}
+	c, _ := alphabet.CheckCharacter("prize")
+	if _, err := testService.CreateShortURL(ctx, "https://example.net", "prize"+string(c), time.Time{}, service.DedupeReuse); err != nil {
+		t.Errorf("CreateShortURL failed: %v", err)
+	}
+
+	// Aliases stored before the option was enabled keep resolving.
+	if _, err := memStorage.SaveURL(ctx, storage.URL{ShortURL: "legacy", OriginalURL: "https://example.org"}); err != nil {
+		t.Fatalf("Failed to save url to memory storage %v", err)
+	}
+	if originalURL, err := testService.GetOriginalURL(ctx, "legacy"); err != nil || originalURL != "https://example.org" {
+		t.Errorf("Expected the legacy alias to resolve, got %q, %v", originalURL, err)
+	}
+	errs, err := testService.ImportURLs(ctx, []storage.URL{{ShortURL: "old-promo", OriginalURL: "https://example.org/promo"}})
+	if err != nil || errs[0] != nil {
+		t.Fatalf("Expected an alias without check character to be imported, got %v, %v", errs, err)
+	}
+	if _, err := testService.GetOriginalURL(ctx, "old-promo"); err != nil {
+		t.Errorf("Expected the imported alias to resolve, got %v", err)
+	}
+}
+
+func TestAliasPool_InMemory(t *testing.T) {
+	cfg := config.MustLoad()
+	ctx := context.Background()
+
+	memStorage := memory.New()
+	testService := service.NewURLShortenerService(memStorage, cfg.ShortURLLength,
+		service.WithAliasPool(memStorage, 20, 5),
+	)
+
+	filled, err := testService.FillAliasPool(ctx)
+	if err != nil {
+		t.Fatalf("FillAliasPool failed: %v", err)
+	}
+	if filled != 20 {
+		t.Fatalf("Expected 20 pooled aliases, got %d", filled)
+	}
+	// The pool is above the watermark.
+	if filled, err := testService.FillAliasPool(ctx); err != nil || filled != 0 {
+		t.Fatalf("Expected no refill, got %d, %v", filled, err)
+	}
+
+	url, err := testService.CreateShortURL(ctx, "https://example.com", "", time.Time{}, service.DedupeReuse)
+	if err != nil {
+		t.Fatalf("CreateShortURL failed: %v", err)
+	}
+	results, err := testService.CreateShortURLs(ctx, []service.CreateRequest{
+		{OriginalURL: "https://example.com/1"},
+		{OriginalURL: "https://example.com/2", CustomAlias: "custom"},
+		{OriginalURL: "https://example.com/3"},
+	})
+	if err != nil {
+		t.Fatalf("CreateShortURLs failed: %v", err)
+	}
+	for _, result := range results {
+		if result.Err != nil {
+			t.Fatalf("CreateShortURLs failed: %v", result.Err)
+		}
+	}
+
+	if size, _ := memStorage.PoolSize(ctx); size != 17 {
+		t.Errorf("Expected 17 pooled aliases left, got %d", size)
+	}
+
+	// Aliases that end up unused go back to the pool.
+	if reused, err := testService.CreateShortURL(ctx, "https://example.com", "", time.Time{}, service.DedupeReuse); err != nil || reused.ShortURL != url.ShortURL {
+		t.Fatalf("Expected the existing alias %q, got %q, %v", url.ShortURL, reused.ShortURL, err)
+	}
+	results2, err := testService.CreateShortURLs(ctx, []service.CreateRequest{
+		{OriginalURL: "not a url"},
+		{OriginalURL: "https://example.com/1"},
+		{OriginalURL: "https://example.com/4", CustomAlias: "custom"},
+	})
+	if err != nil {
+		t.Fatalf("CreateShortURLs failed: %v", err)
+	}
+	if results2[1].Err != nil || results2[1].URL.ShortURL != results[0].URL.ShortURL {
+		t.Errorf("Expected the existing alias %q, got %+v", results[0].URL.ShortURL, results2[1])
+	}
+	if size, _ := memStorage.PoolSize(ctx); size != 17 {
+		t.Errorf("Expected 17 pooled aliases left after reusing existing urls, got %d", size)
+	}
+	// Pooled aliases are never handed out twice.
+	rest, _ := memStorage.TakePoolAliases(ctx, 20)
+	for _, alias := range append(rest, results[0].URL.ShortURL, results[2].URL.ShortURL) {
+		if alias == url.ShortURL {
+			t.Errorf("Alias %q was handed out twice", alias)
+		}
+	}
+
+	if filled, err := testService.FillAliasPool(ctx); err != nil || filled != 20 {
+		t.Errorf("Expected the empty pool to be refilled with 20 aliases, got %d, %v", filled, err)
+	}
+}
+
+func TestAliasPool_Postgres(t *testing.T) {
+	cfg := config.MustLoad()
+	ctx := context.Background()
+	pgStorage := newTestPostgresStorage(t, *cfg)
+	defer func() {
+		if err := pgStorage.Close(); err != nil {
+			t.Fatalf("failed to close database connection: %v", err)
+		}
+	}()
+
+	if _, err := pgStorage.SaveURL(ctx, storage.URL{ShortURL: "used", OriginalURL: "https://example.com"}); err != nil {
+		t.Fatalf("Failed to save url to database %v", err)
+	}
+	aliases := []string{"used"}
+	for i := 0; i < 20; i++ {
+		aliases = append(aliases, fmt.Sprintf("pooled%d", i))
+	}
+	// Used and already pooled aliases are skipped.
+	if added, err := pgStorage.AddPoolAliases(ctx, aliases); err != nil || added != 20 {
+		t.Fatalf("Expected 20 pooled aliases, got %d, %v", added, err)
+	}
+	if added, err := pgStorage.AddPoolAliases(ctx, aliases); err != nil || added != 0 {
+		t.Errorf("Expected no aliases to be added twice, got %d, %v", added, err)
+	}
+
+	// Concurrent takes never get the same alias. Together they take fewer
+	// aliases than pooled, so each gets all it asked for.
+	const workers = 5
+	taken := make(chan []string, workers)
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			aliases, err := pgStorage.TakePoolAliases(ctx, 3)
+			if err != nil {
+				errs <- err
+				return
+			}
+			taken <- aliases
+		}()
+	}
+	seen := make(map[string]struct{})
+	for i := 0; i < workers; i++ {
+		select {
+		case err := <-errs:
+			t.Fatalf("TakePoolAliases failed: %v", err)
+		case aliases := <-taken:
+			if len(aliases) != 3 {
+				t.Errorf("Expected 3 aliases, got %v", aliases)
+			}
+			for _, alias := range aliases {
+				if _, ok := seen[alias]; ok {
+					t.Errorf("Alias %q was taken twice", alias)
+				}
+				seen[alias] = struct{}{}
+			}
+		}
+	}
+
+	if size, err := pgStorage.PoolSize(ctx); err != nil || size != 5 {
+		t.Errorf("Expected 5 pooled aliases left, got %d, %v", size, err)
+	}
+	if aliases, err := pgStorage.TakePoolAliases(ctx, 10); err != nil || len(aliases) != 5 {
+		t.Errorf("Expected the remaining 5 aliases, got %v, %v", aliases, err)
+	}
+}
+
+func TestURLNormalization_InMemory(t *testing.T) {
+	cfg := config.MustLoad()
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		policy   service.URLPolicy
+		input    string
+		expected string
+	}{
+		{name: "lowercase scheme and host", input: "HTTP://Example.COM/Path", expected: "http://example.com/Path"},
+		{name: "default port", input: "https://example.com:443/a", expected: "https://example.com/a"},
+		{name: "other port", input: "https://example.com:8443/a", expected: "https://example.com:8443/a"},
+		{name: "idn", input: "https://пример.рф/путь", expected: "https://xn--e1afmkfd.xn--p1ai/%D0%BF%D1%83%D1%82%D1%8C"},
+		{name: "ipv6", input: "http://[::1]:80/", expected: "http://[::1]/"},
+		{name: "spaces", input: "  https://example.com/a  ", expected: "https://example.com/a"},
+		{name: "trailing slash kept", input: "https://example.com/a/", expected: "https://example.com/a/"},
+		{name: "trailing slash", policy: service.URLPolicy{StripTrailingSlash: true}, input: "https://example.com/a/", expected: "https://example.com/a"},
+		{name: "root trailing slash", policy: service.URLPolicy{StripTrailingSlash: true}, input: "https://example.com/", expected: "https://example.com"},
+		{name: "query kept", input: "https://example.com/?b=1&a=2", expected: "https://example.com/?b=1&a=2"},
+		{name: "sorted query", policy: service.URLPolicy{SortQuery: true}, input: "https://example.com/?b=1&a=2&b=0", expected: "https://example.com/?a=2&b=1&b=0"},
+		{name: "custom scheme", policy: service.URLPolicy{AllowedSchemes: []string{"ftp"}}, input: "FTP://example.com/file", expected: "ftp://example.com/file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testService := service.NewURLShortenerService(memory.New(), cfg.ShortURLLength, service.WithURLPolicy(tt.policy))
+			url, err := testService.CreateShortURL(ctx, tt.input, "", time.Time{}, service.DedupeReuse)
+			if err != nil {
+				t.Fatalf("CreateShortURL failed: %v", err)
+			}
+			if url.CanonicalURL != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, url.CanonicalURL)
+			}
+			if url.OriginalURL != strings.TrimSpace(tt.input) {
+				t.Errorf("Expected original url %q, got %q", strings.TrimSpace(tt.input), url.OriginalURL)
+			}
+		})
+	}
+}
+
+func TestCanonicalDeduplication_InMemory(t *testing.T) {
+	ctx := context.Background()
+	testService := service.NewURLShortenerService(memory.New(), 8, service.WithURLPolicy(service.URLPolicy{SortQuery: true}))
+
+	first, err := testService.CreateShortURL(ctx, "https://a.com/x?b=1&a=2", "", time.Time{}, service.DedupeReuse)
+	if err != nil {
+		t.Fatalf("CreateShortURL failed: %v", err)
+	}
+	second, err := testService.CreateShortURL(ctx, "HTTPS://A.com:443/x?a=2&b=1", "", time.Time{}, service.DedupeReuse)
+	if err != nil {
+		t.Fatalf("CreateShortURL failed: %v", err)
+	}
+	if second.ShortURL != first.ShortURL {
+		t.Errorf("Expected short url %q, got %q", first.ShortURL, second.ShortURL)
+	}
+
+	results, err := testService.CreateShortURLs(ctx, []service.CreateRequest{{OriginalURL: "https://a.com:443/x?a=2&b=1"}})
+	if err != nil {
+		t.Fatalf("CreateShortURLs failed: %v", err)
+	}
+	if results[0].Err != nil || results[0].URL.ShortURL != first.ShortURL {
+		t.Errorf("Expected short url %q, got %+v", first.ShortURL, results[0])
+	}
+
+	originalURL, err := testService.GetOriginalURL(ctx, first.ShortURL)
+	if err != nil {
+		t.Fatalf("GetOriginalURL failed: %v", err)
+	}
+	if originalURL != "https://a.com/x?b=1&a=2" {
+		t.Errorf("Expected redirect to the first submitted url, got %q", originalURL)
+	}
+}
+
+func TestURLValidation_InMemory(t *testing.T) {
+	cfg := config.MustLoad()
+
+	memStorage := memory.New()
+	s := newTestGRPCServer(t, memStorage, *cfg)
+	lis, _ := newBufConnListener(t, s)
+	client, close := newTestClient(t, lis)
+	defer close()
+	defer s.GracefulStop()
+
+	for _, input := range []string{
+		"",
+		"not a url",
+		"javascript:alert(1)",
+		"ftp://example.com/file",
+		"https://",
+		"https://example.com:99999/",
+		"https://exa mple.com/",
+	} {
+		_, err := client.CreateShortURL(context.Background(), &mygrpc.CreateShortURLRequest{OriginalUrl: input})
+		st := status.Convert(err)
+		if st.Code() != codes.InvalidArgument {
+			t.Errorf("%q: expected InvalidArgument, got %v", input, err)
+			continue
+		}
+		var violations []*errdetails.BadRequest_FieldViolation
+		for _, detail := range st.Details() {
+			if br, ok := detail.(*errdetails.BadRequest); ok {
+				violations = append(violations, br.FieldViolations...)
+			}
+		}
+		if len(violations) != 1 || violations[0].Field != "original_url" {
+			t.Errorf("%q: expected a field violation of original_url, got %v", input, violations)
+		}
+	}
+}
+
+func TestDedupeMode_InMemory(t *testing.T) {
+	cfg := config.MustLoad()
+	ctx := context.Background()
+
+	memStorage := memory.New()
+	s := newTestGRPCServer(t, memStorage, *cfg)
+	lis, _ := newBufConnListener(t, s)
+	client, close := newTestClient(t, lis)
+	defer close()
+	defer s.GracefulStop()
+
+	create := func(mode mygrpc.DedupeMode) (string, error) {
+		resp, err := client.CreateShortURL(ctx, &mygrpc.CreateShortURLRequest{
+			OriginalUrl: "https://example.com/campaign",
+			DedupeMode:  mode,
+		})
+		return resp.GetShortUrl(), err
+	}
+
+	first, err := create(mygrpc.DedupeMode_DEDUPE_MODE_UNSPECIFIED)
+	if err != nil {
+		t.Fatalf("CreateShortURL failed: %v", err)
+	}
+	second, err := create(mygrpc.DedupeMode_DEDUPE_MODE_ALWAYS_NEW)
+	if err != nil {
+		t.Fatalf("CreateShortURL failed: %v", err)
+	}
+	if second == first {
+		t.Errorf("Expected a new short url, got %q again", first)
+	}
+	if reused, err := create(mygrpc.DedupeMode_DEDUPE_MODE_REUSE); err != nil || reused != first {
+		t.Errorf("Expected %q to be reused, got %q, %v", first, reused, err)
+	}
+	if _, err := create(mygrpc.DedupeMode_DEDUPE_MODE_FAIL_IF_EXISTS); status.Code(err) != codes.AlreadyExists {
+		t.Errorf("Expected AlreadyExists, got %v", err)
+	}
+	if _, err := create(mygrpc.DedupeMode(42)); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument, got %v", err)
+	}
+
+	for _, alias := range []string{first, second} {
+		if url, err := memStorage.GetURL(ctx, alias); err != nil || url != "https://example.com/campaign" {
+			t.Errorf("Expected %s to point to the original url, got %s, %v", alias, url, err)
+		}
+	}
+
+	// The oldest remaining alias is reused once the first one is deleted.
+	if _, err := client.DeleteShortURL(ctx, &mygrpc.DeleteShortURLRequest{ShortUrl: first}); err != nil {
+		t.Fatalf("DeleteShortURL failed: %v", err)
+	}
+	if reused, err := create(mygrpc.DedupeMode_DEDUPE_MODE_REUSE); err != nil || reused != second {
+		t.Errorf("Expected %q to be reused, got %q, %v", second, reused, err)
+	}
+}
+
+func TestCustomAliasPolicy_InMemory(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		policy   service.AliasPolicy
+		alias    string
+		expected string // empty if the alias is rejected
+	}{
+		{name: "valid", alias: "summer-sale_2024", expected: "summer-sale_2024"},
+		{name: "spaces", alias: "   ", expected: ""},
+		{name: "slash", alias: "a/b", expected: ""},
+		{name: "too long", alias: strings.Repeat("a", 2000), expected: ""},
+		{name: "too short", policy: service.AliasPolicy{MinLength: 3}, alias: "ab", expected: ""},
+		{name: "characters", policy: service.AliasPolicy{Characters: alias.MustParseAlphabet("base62")}, alias: "a-b", expected: ""},
+		{name: "reserved", policy: service.AliasPolicy{Reserved: []string{"api", "admin"}}, alias: "API", expected: ""},
+		{name: "lower", policy: service.AliasPolicy{Case: service.AliasCaseLower}, alias: "Promo", expected: "promo"},
+		{name: "reject upper", policy: service.AliasPolicy{Case: service.AliasCaseRejectUpper}, alias: "Promo", expected: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testService := service.NewURLShortenerService(memory.New(), 8, service.WithAliasPolicy(tt.policy))
+			url, err := testService.CreateShortURL(ctx, "https://example.com", tt.alias, time.Time{}, service.DedupeReuse)
+			if tt.expected == "" {
+				var fieldErr *service.FieldError
+				if !errors.As(err, &fieldErr) || fieldErr.Field != "custom_alias" || !errors.Is(err, service.ErrInvalidArgument) {
+					t.Errorf("Expected invalid custom_alias, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateShortURL failed: %v", err)
+			}
+			if url.ShortURL != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, url.ShortURL)
+			}
+		})
+	}
+}
+
+func TestLookupByOriginalURL_InMemory(t *testing.T) {
+	cfg := config.MustLoad()
+	ctx := context.Background()
+
+	memStorage := memory.New()
+	s := newTestGRPCServer(t, memStorage, *cfg)
+	lis, _ := newBufConnListener(t, s)
+	client, close := newTestClient(t, lis)
+	defer close()
+	defer s.GracefulStop()
+
+	_, err := client.LookupByOriginalURL(ctx, &mygrpc.LookupByOriginalURLRequest{OriginalUrl: "https://example.com/page"})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("Expected NotFound, got %v", err)
+	}
+	if urls, _ := memStorage.ListURLs(ctx, storage.ListQuery{Limit: 10}); len(urls) != 0 {
+		t.Fatalf("Expected lookup to create nothing, got %v", urls)
+	}
+
+	created, err := client.CreateShortURL(ctx, &mygrpc.CreateShortURLRequest{OriginalUrl: "https://example.com/page"})
+	if err != nil {
+		t.Fatalf("CreateShortURL failed: %v", err)
+	}
+
+	resp, err := client.LookupByOriginalURL(ctx, &mygrpc.LookupByOriginalURLRequest{OriginalUrl: "HTTPS://Example.com:443/page"})
+	if err != nil {
+		t.Fatalf("LookupByOriginalURL failed: %v", err)
+	}
+	if resp.Url.ShortUrl != created.ShortUrl || resp.Url.OriginalUrl != "https://example.com/page" {
+		t.Errorf("Expected %s for https://example.com/page, got %v", created.ShortUrl, resp.Url)
+	}
+
+	batch, err := client.LookupByOriginalURLs(ctx, &mygrpc.LookupByOriginalURLsRequest{
+		OriginalUrls: []string{"https://example.com/other", "https://EXAMPLE.com/page", "not a url"},
+	})
+	if err != nil {
+		t.Fatalf("LookupByOriginalURLs failed: %v", err)
+	}
+	expectedCodes := []codes.Code{codes.NotFound, codes.OK, codes.InvalidArgument}
+	for i, result := range batch.Results {
+		if codes.Code(result.Code) != expectedCodes[i] {
+			t.Errorf("item %d: expected %s, got %s (%s)", i, expectedCodes[i], codes.Code(result.Code), result.Message)
+		}
+	}
+	if batch.Results[1].GetUrl().GetShortUrl() != created.ShortUrl {
+		t.Errorf("Expected %s, got %v", created.ShortUrl, batch.Results[1].Url)
+	}
+}